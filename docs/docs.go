@@ -15,98 +15,43 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/add_human": {
-            "put": {
-                "description": "Create a new human with auto-filled age, gender, nationality",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "humans"
-                ],
-                "summary": "Create a human",
-                "parameters": [
-                    {
-                        "description": "Add Human payload",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/apiserver.addHumanRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/model.Human"
-                        }
-                    },
-                    "400": {
-                        "description": "name and surname required",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/delete_human": {
+        "/admin/enrichment-cache": {
             "delete": {
-                "description": "Delete a human record by ID",
-                "consumes": [
+                "description": "Remove cached agify/genderize/nationalize responses for a name, or all of them when name is omitted",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "humans"
+                    "admin"
                 ],
-                "summary": "Delete human",
+                "summary": "Invalidate enrichment cache",
                 "parameters": [
                     {
-                        "description": "Delete Human request",
-                        "name": "id",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/apiserver.deleteHumanRequest"
-                        }
+                        "type": "string",
+                        "description": "Name to invalidate",
+                        "name": "name",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "415": {
-                        "description": "Unsupported Media Type",
-                        "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apiserver.invalidateCacheResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     }
                 }
             }
         },
-        "/get_humans": {
+        "/humans": {
             "get": {
-                "description": "Retrieve humans with optional filtering and pagination",
+                "description": "Retrieve humans with optional filtering, sorting and pagination.\nOffset mode (page, page_size) returns an array unless the X-Envelope: true header asks for a humansPage.\nPassing cursor, empty for the first page, switches to keyset pagination and always returns a humansPage;\nits next_cursor is passed as cursor to get the following page. Without sort cursor mode lists newest first.",
                 "consumes": [
                     "application/json"
                 ],
@@ -148,6 +93,29 @@ const docTemplate = `{
                         "name": "nationality",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated countries matched against every nationality candidate, e.g. RU,UA",
+                        "name": "nationality_any",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum candidate probability for nationality_any",
+                        "name": "nationality_min_probability",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "enriched",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Enrichment status filter",
+                        "name": "enrichment_status",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Minimum age filter",
@@ -160,6 +128,52 @@ const docTemplate = `{
                         "name": "max_age",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "example": "nationality in ('RU','KZ'",
+                        "description": "Filter expression combined with the other filters by AND",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return deleted humans that are not purged yet, with deleted_at set",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-05-25T00:00:00Z",
+                        "description": "Only humans created after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only humans created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-05-25T00:00:00Z",
+                        "description": "Only humans changed at or after this RFC 3339 time",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only humans last changed before this RFC 3339 time",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "age,-surname",
+                        "description": "Comma-separated sort columns, - for descending: id, name, surname, patronymic, age, gender, nationality, created_at, updated_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Page number",
@@ -171,6 +185,18 @@ const docTemplate = `{
                         "description": "Page size",
                         "name": "page_size",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Keyset pagination cursor; empty for the first page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return a humansPage instead of an array",
+                        "name": "X-Envelope",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -183,25 +209,162 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new human. Age, gender and nationality are filled in the background;\npoll the record until enrichment_status becomes enriched or failed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Create a human",
+                "parameters": [
+                    {
+                        "description": "Add Human payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.addHumanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Human"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the human"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created human"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a human record by ID. Deprecated: use DELETE /humans/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Delete human (deprecated)",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Delete Human request",
+                        "name": "id",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.deleteHumanRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the human as last read; the change is rejected with 412 when the human has changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
                     }
                 }
-            }
-        },
-        "/update_human": {
+            },
             "patch": {
-                "description": "Update human fields by ID",
+                "description": "Update human fields by ID. Deprecated: use PATCH /humans/{id}",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
                     "humans"
                 ],
-                "summary": "Update human",
+                "summary": "Update human (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Update Human request",
@@ -211,6 +374,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/apiserver.updateHumanRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the human as last read; the change is rejected with 412 when the human has changed since",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -223,67 +392,1605 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     },
                     "415": {
                         "description": "Unsupported Media Type",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "apiserver.addHumanRequest": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "description": "имя\nrequired: true",
-                    "type": "string",
-                    "example": "John"
-                },
-                "patronymic": {
-                    "description": "отчество\nrequired: false",
-                    "type": "string",
-                    "example": "Johnny"
-                },
-                "surname": {
-                    "description": "фамилия\nrequired: true",
-                    "type": "string",
-                    "example": "Doe"
-                }
-            }
-        },
-        "apiserver.deleteHumanRequest": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "description": "ID человека\nrequired: true",
-                    "type": "integer",
-                    "example": 1
-                }
-            }
         },
-        "apiserver.updateHumanRequest": {
+        "/humans/batch": {
+            "post": {
+                "description": "Create many humans in one transaction. Invalid items are reported and skipped;\nvalid ones are enriched in the background with batched provider requests",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Create humans in bulk",
+                "parameters": [
+                    {
+                        "description": "Humans to add",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/apiserver.addHumanRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.batchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/duplicates": {
+            "get": {
+                "description": "Group humans whose normalized full names are equal or similar by trigrams\n(at least DUPLICATE_SIMILARITY_THRESHOLD). A page examines up to page_size humans in ID order\nand clusters them with their most similar later humans; clusters with the most similar names come first.\nnext_after of the response is passed as after to examine the following humans.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Find duplicates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Examine humans with a greater ID; next_after of the previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of humans examined (default 1000, at most 5000)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.duplicatesPage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/enrich": {
+            "post": {
+                "description": "Move humans with the given status back to pending and enrich them again in the background.\nOne request resets at most ENRICH_REFRESH_BATCH humans; while more is set, repeat it to reset the rest.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Re-enrich humans",
+                "parameters": [
+                    {
+                        "enum": [
+                            "failed",
+                            "enriched"
+                        ],
+                        "type": "string",
+                        "default": "failed",
+                        "description": "Enrichment status to re-process",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only humans enriched longer ago than this duration, e.g. 720h",
+                        "name": "older_than",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.enrichResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/export": {
+            "get": {
+                "description": "Stream all humans matching the filter as CSV, NDJSON or XLSX. Accepts the filters\nof GET /humans; pagination is ignored. Nationality candidates and enrichment sources are not exported.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Export humans",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name filter",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname filter",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Patronymic filter",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Gender filter",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nationality filter",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated countries matched against every nationality candidate, e.g. RU,UA",
+                        "name": "nationality_any",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum candidate probability for nationality_any",
+                        "name": "nationality_min_probability",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "enriched",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Enrichment status filter",
+                        "name": "enrichment_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum age filter",
+                        "name": "min_age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum age filter",
+                        "name": "max_age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "nationality in ('RU','KZ'",
+                        "description": "Filter expression combined with the other filters by AND",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also export deleted humans that are not purged yet",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-05-25T00:00:00Z",
+                        "description": "Only humans created after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only humans created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-05-25T00:00:00Z",
+                        "description": "Only humans changed at or after this RFC 3339 time",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only humans last changed before this RFC 3339 time",
+                        "name": "updated_before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "attachment; filename=humans.csv"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/import": {
+            "post": {
+                "description": "Upload humans as CSV (header with name, surname, patronymic columns) or NDJSON\n(one addHumanRequest object per line). Rows are validated like POST /humans,\ninserted in chunks and enriched in the background. Poll the returned job for progress.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Import humans",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.importJobStatus"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the import job"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/import/{jobID}": {
+            "get": {
+                "description": "Progress and per-row errors of an import started with POST /humans/import",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Get import job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Import job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.importJobStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/merge": {
+            "post": {
+                "description": "Merge records into the survivor in one transaction: empty fields of the survivor are filled\nfrom the merged records, which are then deleted. Snapshots of the merged records are kept in people_merges.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Merge humans",
+                "parameters": [
+                    {
+                        "description": "Survivor and merged IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.mergeHumansRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Human"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/search": {
+            "get": {
+                "description": "Fuzzy search across name, surname and patronymic by trigram similarity.\nCyrillic and Latin spellings match each other, e.g. Dmitriy finds Дмитрий.\nHits below SEARCH_SIMILARITY_THRESHOLD are skipped; the best matches come first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Search humans",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text, e.g. Ivonov",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of hits (default 20, at most 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SearchHit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/{id}": {
+            "get": {
+                "description": "Retrieve a human record by ID. Supports conditional requests with If-None-Match and If-Modified-Since.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Get human",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Human ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the cached human",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time the cached human was last modified, in HTTP date format",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Human"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the human"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last change of the human"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace all human fields by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Replace human",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Human ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Replace Human request",
+                        "name": "human",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.replaceHumanRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the human as last read; the change is rejected with 412 when the human has changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Human"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the human"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Mark a human as deleted. The human is hidden from every endpoint except GET /humans with include_deleted\nand the history, can be restored with POST /humans/{id}/restore and is removed for good after DELETED_RETENTION",
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Delete human by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Human ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the human as last read; the change is rejected with 412 when the human has changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update human fields by ID. With application/json only non-empty fields are changed.\nWith application/merge-patch+json (RFC 7396) null clears a field; with application/json-patch+json (RFC 6902)\nthe body is an array of operations on paths like /age, and remove clears a field. Name and surname cannot be cleared.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Patch human",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Human ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Patch Human request, a merge patch or an array of patch.Operation",
+                        "name": "human",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.patchHumanRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the human as last read; the change is rejected with 412 when the human has changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Human"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the human"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/{id}/enrich": {
+            "post": {
+                "description": "Move a human back to pending and enrich it again in the background",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Re-enrich human",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Human ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Human"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/{id}/history": {
+            "get": {
+                "description": "List every change of a human, oldest first: who made it (X-Actor header of the request),\nthe request ID and the old and new values of the changed fields. The history of a deleted human stays available.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Get human history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Human ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.HistoryEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/humans/{id}/restore": {
+            "post": {
+                "description": "Clear the deletion mark of a human that was not purged yet. Restoring a human that is not deleted changes nothing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Restore human",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Human ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the deleted human as last read; the change is rejected with 412 when the human has changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Human"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the human"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/humans": {
+            "get": {
+                "description": "Same as GET /humans, but always returns a humansPage with the total number of matches.\nAbove COUNT_EXACT_LIMIT matches the total is the planner estimate and total_estimated is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "humans"
+                ],
+                "summary": "Get humans page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name filter",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname filter",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Patronymic filter",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Gender filter",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nationality filter",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated countries matched against every nationality candidate, e.g. RU,UA",
+                        "name": "nationality_any",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum candidate probability for nationality_any",
+                        "name": "nationality_min_probability",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "enriched",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Enrichment status filter",
+                        "name": "enrichment_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum age filter",
+                        "name": "min_age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum age filter",
+                        "name": "max_age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "nationality in ('RU','KZ'",
+                        "description": "Filter expression combined with the other filters by AND",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return deleted humans that are not purged yet, with deleted_at set",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-05-25T00:00:00Z",
+                        "description": "Only humans created after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only humans created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-05-25T00:00:00Z",
+                        "description": "Only humans changed at or after this RFC 3339 time",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only humans last changed before this RFC 3339 time",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "age,-surname",
+                        "description": "Comma-separated sort columns, - for descending: id, name, surname, patronymic, age, gender, nationality, created_at, updated_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Keyset pagination cursor; empty for the first page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.humansPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apiserver.addHumanRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "surname"
+            ],
+            "properties": {
+                "name": {
+                    "description": "имя\nrequired: true",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "John"
+                },
+                "patronymic": {
+                    "description": "отчество\nrequired: false",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Johnny"
+                },
+                "surname": {
+                    "description": "фамилия\nrequired: true",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Doe"
+                }
+            }
+        },
+        "apiserver.batchItemResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "description": "ошибки валидации элемента",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.fieldError"
+                    }
+                },
+                "id": {
+                    "description": "ID созданной записи",
+                    "type": "integer",
+                    "example": 1
+                },
+                "index": {
+                    "description": "позиция элемента в запросе",
+                    "type": "integer",
+                    "example": 0
+                },
+                "location": {
+                    "description": "URL созданной записи",
+                    "type": "string",
+                    "example": "/humans/1"
+                },
+                "status": {
+                    "description": "accepted или invalid",
+                    "type": "string",
+                    "example": "accepted"
+                }
+            }
+        },
+        "apiserver.batchResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.batchItemResult"
+                    }
+                },
+                "rejected": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "apiserver.deleteHumanRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID человека\nrequired: true",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "apiserver.duplicatesPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DuplicateCluster"
+                    }
+                },
+                "next_after": {
+                    "description": "значение after для следующей страницы; отсутствует, когда просмотрены все записи",
+                    "type": "integer",
+                    "example": 1042
+                }
+            }
+        },
+        "apiserver.enrichResponse": {
+            "type": "object",
+            "properties": {
+                "more": {
+                    "description": "подходящие записи остались: повторите запрос, чтобы поставить в очередь следующую порцию",
+                    "type": "boolean",
+                    "example": false
+                },
+                "queued": {
+                    "description": "количество записей, поставленных в очередь",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "apiserver.fieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "required"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "name"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "name is required"
+                },
+                "position": {
+                    "description": "позиция символа в значении поля, начиная с 1",
+                    "type": "integer",
+                    "example": 17
+                }
+            }
+        },
+        "apiserver.humansPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Human"
+                    }
+                },
+                "next_cursor": {
+                    "description": "токен следующей страницы в режиме cursor; отсутствует на последней странице",
+                    "type": "string",
+                    "example": "eyJzIjoiLWlkIiwidiI6WyI0MiJdfQ"
+                },
+                "page": {
+                    "description": "номер страницы; отсутствует в режиме cursor",
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "description": "количество записей, подходящих под фильтр",
+                    "type": "integer",
+                    "example": 1342
+                },
+                "total_estimated": {
+                    "description": "total — оценка планировщика, а не точный подсчёт",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "apiserver.importJobStatus": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 1498
+                },
+                "error": {
+                    "description": "причина аварийного завершения",
+                    "type": "string",
+                    "example": ""
+                },
+                "errors": {
+                    "description": "ошибки строк; хранятся первые 1000",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.importRowError"
+                    }
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2025-05-25T12:01:00Z"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "text/csv"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f2b8c0e9a7d4e1f8b3c6a2d1e0f9a8b"
+                },
+                "processed": {
+                    "description": "обработано строк",
+                    "type": "integer",
+                    "example": 1500
+                },
+                "rejected": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "running"
+                }
+            }
+        },
+        "apiserver.importRowError": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.fieldError"
+                    }
+                },
+                "row": {
+                    "description": "номер строки данных, начиная с 1",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "apiserver.invalidateCacheResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "description": "количество удалённых записей в Postgres",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "apiserver.mergeHumansRequest": {
+            "type": "object",
+            "properties": {
+                "merged_ids": {
+                    "description": "ID поглощаемых записей; их значения заполняют пустые поля в этом порядке\nrequired: true",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        2,
+                        3
+                    ]
+                },
+                "survivor_id": {
+                    "description": "ID записи, которая остаётся\nrequired: true",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "apiserver.patchHumanRequest": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "description": "возраст\nrequired: false",
+                    "type": "integer",
+                    "maximum": 150,
+                    "minimum": 0,
+                    "example": 30
+                },
+                "gender": {
+                    "description": "пол\nrequired: false",
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female",
+                        "unknown"
+                    ],
+                    "example": "male"
+                },
+                "name": {
+                    "description": "имя\nrequired: false",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "John"
+                },
+                "nationality": {
+                    "description": "национальность\nrequired: false",
+                    "type": "string",
+                    "example": "RU"
+                },
+                "patronymic": {
+                    "description": "отчество\nrequired: false",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Johnny"
+                },
+                "surname": {
+                    "description": "фамилия\nrequired: false",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Doe"
+                }
+            }
+        },
+        "apiserver.problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "human_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "human not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.fieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/humans/1"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "host/abcdef-000001"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "apiserver.replaceHumanRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "surname"
+            ],
+            "properties": {
+                "age": {
+                    "description": "возраст\nrequired: false",
+                    "type": "integer",
+                    "maximum": 150,
+                    "minimum": 0,
+                    "example": 30
+                },
+                "gender": {
+                    "description": "пол\nrequired: false",
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female",
+                        "unknown"
+                    ],
+                    "example": "male"
+                },
+                "name": {
+                    "description": "имя\nrequired: true",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "John"
+                },
+                "nationality": {
+                    "description": "национальность\nrequired: false",
+                    "type": "string",
+                    "example": "RU"
+                },
+                "patronymic": {
+                    "description": "отчество\nrequired: false",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Johnny"
+                },
+                "surname": {
+                    "description": "фамилия\nrequired: true",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Doe"
+                }
+            }
+        },
+        "apiserver.updateHumanRequest": {
             "type": "object",
+            "required": [
+                "id"
+            ],
             "properties": {
                 "age": {
                     "description": "возраст\nrequired: false",
                     "type": "integer",
+                    "maximum": 150,
+                    "minimum": 0,
                     "example": 30
                 },
                 "gender": {
                     "description": "пол\nrequired: false",
                     "type": "string",
+                    "enum": [
+                        "male",
+                        "female",
+                        "unknown"
+                    ],
                     "example": "male"
                 },
                 "id": {
@@ -294,6 +2001,7 @@ const docTemplate = `{
                 "name": {
                     "description": "имя\nrequired: false",
                     "type": "string",
+                    "maxLength": 255,
                     "example": "John"
                 },
                 "nationality": {
@@ -304,15 +2012,98 @@ const docTemplate = `{
                 "patronymic": {
                     "description": "отчество\nrequired: false",
                     "type": "string",
+                    "maxLength": 255,
                     "example": "Johnny"
                 },
                 "surname": {
                     "description": "фамилия\nrequired: false",
                     "type": "string",
+                    "maxLength": 255,
                     "example": "Doe"
                 }
             }
         },
+        "model.DuplicateCluster": {
+            "type": "object",
+            "properties": {
+                "humans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Human"
+                    }
+                },
+                "score": {
+                    "description": "Score is the highest similarity of the search keys of two humans in the cluster, from 0 to 1",
+                    "type": "number",
+                    "example": 0.72
+                }
+            }
+        },
+        "model.Enrichment": {
+            "type": "object",
+            "properties": {
+                "attribute": {
+                    "type": "string",
+                    "example": "gender"
+                },
+                "fetched_at": {
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "probability": {
+                    "type": "number",
+                    "example": 0.98
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "genderize"
+                },
+                "sample_count": {
+                    "type": "integer",
+                    "example": 1234
+                },
+                "value": {
+                    "type": "string",
+                    "example": "male"
+                }
+            }
+        },
+        "model.HistoryEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "actor": {
+                    "description": "Actor is taken from the X-Actor header; background enrichment uses \"enrichment\"",
+                    "type": "string",
+                    "example": "alice"
+                },
+                "changed_at": {
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "human_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "new_values": {
+                    "type": "object"
+                },
+                "old_values": {
+                    "type": "object"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "host/abcdef-000001"
+                }
+            }
+        },
         "model.Human": {
             "type": "object",
             "properties": {
@@ -320,6 +2111,122 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 25
                 },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt is set for humans that were deleted and can still be restored",
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "enriched_at": {
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "enrichment": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Enrichment"
+                    }
+                },
+                "enrichment_error": {
+                    "type": "string",
+                    "example": ""
+                },
+                "enrichment_status": {
+                    "type": "string",
+                    "example": "enriched"
+                },
+                "gender": {
+                    "type": "string",
+                    "example": "male"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "nationalities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Nationality"
+                    }
+                },
+                "nationality": {
+                    "type": "string",
+                    "example": "RU"
+                },
+                "patronymic": {
+                    "type": "string",
+                    "example": "Ivanovich"
+                },
+                "surname": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt changes with every change of the human, including enrichment",
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "version": {
+                    "description": "Version grows with every change of the human and is sent as its ETag",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "model.Nationality": {
+            "type": "object",
+            "properties": {
+                "country_id": {
+                    "type": "string",
+                    "example": "RU"
+                },
+                "probability": {
+                    "type": "number",
+                    "example": 0.74
+                }
+            }
+        },
+        "model.SearchHit": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt is set for humans that were deleted and can still be restored",
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "enriched_at": {
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "enrichment": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Enrichment"
+                    }
+                },
+                "enrichment_error": {
+                    "type": "string",
+                    "example": ""
+                },
+                "enrichment_status": {
+                    "type": "string",
+                    "example": "enriched"
+                },
                 "gender": {
                     "type": "string",
                     "example": "male"
@@ -332,6 +2239,12 @@ const docTemplate = `{
                     "type": "string",
                     "example": "John"
                 },
+                "nationalities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Nationality"
+                    }
+                },
                 "nationality": {
                     "type": "string",
                     "example": "RU"
@@ -340,9 +2253,24 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Ivanovich"
                 },
+                "score": {
+                    "description": "Score is the best trigram similarity of the name parts to the query, from 0 to 1",
+                    "type": "number",
+                    "example": 0.64
+                },
                 "surname": {
                     "type": "string",
                     "example": "Doe"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt changes with every change of the human, including enrichment",
+                    "type": "string",
+                    "example": "2025-05-25T12:00:00Z"
+                },
+                "version": {
+                    "description": "Version grows with every change of the human and is sent as its ETag",
+                    "type": "integer",
+                    "example": 3
                 }
             }
         }
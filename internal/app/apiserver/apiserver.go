@@ -3,9 +3,13 @@ package apiserver
 import (
 	"context"
 	"effectiveMobile/internal/store/sqlstore"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -18,8 +22,40 @@ func Start(config *Config) error {
 	store := sqlstore.New(db)
 	srv := newServer(store, config)
 	srv.configureRouter()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Фоновые задачи останавливаются после HTTP-сервера, до закрытия пула соединений
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer func() {
+		cancelJobs()
+		srv.wait()
+	}()
+	srv.start(jobsCtx)
+
+	httpServer := &http.Server{
+		Addr:    config.Server.Port,
+		Handler: srv.router,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
 	fmt.Println(config.Server.Port)
-	return http.ListenAndServe(config.Server.Port, srv.router)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 func newDB(dbURL string) (*pgxpool.Pool, error) {
@@ -0,0 +1,82 @@
+package apiserver
+
+import (
+	"effectiveMobile/internal/model"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+const (
+	batchItemAccepted = "accepted"
+	batchItemInvalid  = "invalid"
+)
+
+// addHumansBatch adds many humans at once
+// @Summary Create humans in bulk
+// @Description Create many humans in one transaction. Invalid items are reported and skipped;
+// @Description valid ones are enriched in the background with batched provider requests
+// @Tags humans
+// @Accept json
+// @Produce json
+// @Param body body []addHumanRequest true "Humans to add"
+// @Success 202 {object} batchResponse
+// @Failure 400 {object} problem
+// @Failure 413 {object} problem
+// @Failure 415 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/batch [post]
+func (s *server) addHumansBatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqs []addHumanRequest
+		if err := s.decodeJSON(r, &reqs); err != nil {
+			s.error(w, r, err)
+			return
+		}
+		if len(reqs) > s.config.Server.MaxBatchSize {
+			s.error(w, r, errBatchTooLarge)
+			return
+		}
+
+		resp := batchResponse{Items: make([]batchItemResult, len(reqs))}
+		var (
+			humans  []model.Human
+			indexes []int
+		)
+		for i, req := range reqs {
+			resp.Items[i].Index = i
+			trimNames(&req.Name, &req.Surname, &req.Patronymic)
+			if fields := validationFields(&req); fields != nil {
+				resp.Items[i].Status = batchItemInvalid
+				resp.Items[i].Errors = fields
+				resp.Rejected++
+				continue
+			}
+			humans = append(humans, model.Human{
+				Name:             req.Name,
+				Surname:          req.Surname,
+				Patronymic:       req.Patronymic,
+				Gender:           "unknown",
+				EnrichmentStatus: model.EnrichmentPending,
+			})
+			indexes = append(indexes, i)
+		}
+
+		if err := s.store.Human().AddHumans(r.Context(), humans); err != nil {
+			s.error(w, r, err)
+			return
+		}
+		for j, human := range humans {
+			item := &resp.Items[indexes[j]]
+			item.ID = human.Id
+			item.Location = humanLocation(human.Id)
+			item.Status = batchItemAccepted
+			resp.Accepted++
+		}
+		if len(humans) > 0 {
+			s.pipeline.EnqueueBatch(humans)
+		}
+		s.logger.Info("added humans batch", zap.Int("accepted", resp.Accepted), zap.Int("rejected", resp.Rejected))
+
+		s.respond(w, http.StatusAccepted, resp)
+	}
+}
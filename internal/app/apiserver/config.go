@@ -1,12 +1,18 @@
 package apiserver
 
 import (
+	"effectiveMobile/internal/app/enricher"
 	"github.com/joho/godotenv"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Server struct {
 	Port string
+	// MaxBatchSize ограничивает количество записей в POST /humans/batch
+	MaxBatchSize int
 }
 
 type Postgres struct {
@@ -18,9 +24,65 @@ type Zap struct {
 }
 
 type ExternalService struct {
-	AgifyURL       string
-	GenderizeURL   string
-	NationalizeURL string
+	// Providers перечисляет включённые сервисы обогащения в порядке приоритета
+	Providers []enricher.Provider
+
+	// EnrichTimeout ограничивает одну попытку обогащения человека целиком,
+	// Timeout — одну попытку запроса к внешнему сервису
+	EnrichTimeout    time.Duration
+	Timeout          time.Duration
+	RetryCount       int
+	RetryWaitTime    time.Duration
+	RetryMaxWaitTime time.Duration
+
+	// CacheSize — количество ответов в памяти, CacheTTL — срок жизни ответа
+	CacheSize int
+	CacheTTL  time.Duration
+}
+
+type Enrichment struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	// RetryWait и RetryMaxWait задают паузы между попытками обогащения записи
+	RetryWait     time.Duration
+	RetryMaxWait  time.Duration
+	SweepInterval time.Duration
+	// RefreshAge — возраст обогащения, после которого запись обогащается заново
+	RefreshAge      time.Duration
+	RefreshInterval time.Duration
+	RefreshBatch    int
+	// Transliteration — схема перевода имён в латиницу для сервисов обогащения: icao или gost
+	Transliteration string
+}
+
+type Import struct {
+	// MaxBytes ограничивает размер загружаемого файла
+	MaxBytes int64
+	// ChunkSize — количество строк, вставляемых одной транзакцией
+	ChunkSize int
+	// JobTTL — сколько хранится в памяти статус завершённого импорта
+	JobTTL time.Duration
+}
+
+type Search struct {
+	// NationalityMinProbability — минимальная вероятность кандидата для фильтра nationality_any
+	NationalityMinProbability float64
+	// CountExactLimit — до скольких совпадений total считается точно, дальше берётся оценка планировщика;
+	// 0 — всегда точно
+	CountExactLimit int
+	// SimilarityThreshold — минимальная триграммная похожесть для GET /humans/search
+	SimilarityThreshold float64
+	// DuplicateThreshold — минимальная похожесть ключей поиска для GET /humans/duplicates
+	DuplicateThreshold float64
+}
+
+type Retention struct {
+	// Deleted — сколько хранятся удалённые записи до окончательного удаления; 0 — бессрочно
+	Deleted       time.Duration
+	PurgeInterval time.Duration
+	// PurgeBatch — количество записей, удаляемых одной транзакцией
+	PurgeBatch int
 }
 
 type Config struct {
@@ -28,6 +90,10 @@ type Config struct {
 	Postgres        Postgres
 	Zap             Zap
 	ExternalService ExternalService
+	Enrichment      Enrichment
+	Import          Import
+	Search          Search
+	Retention       Retention
 }
 
 func NewConfig() *Config {
@@ -37,7 +103,8 @@ func NewConfig() *Config {
 
 	return &Config{
 		Server: Server{
-			Port: os.Getenv("SERVER_PORT"),
+			Port:         os.Getenv("SERVER_PORT"),
+			MaxBatchSize: getEnvInt("MAX_BATCH_SIZE", 1000),
 		},
 		Postgres: Postgres{
 			URL: os.Getenv("DATABASE_URL"),
@@ -46,9 +113,97 @@ func NewConfig() *Config {
 			Level: os.Getenv("ZAP_LEVEL"),
 		},
 		ExternalService: ExternalService{
-			AgifyURL:       os.Getenv("AGIFY_URL"),
-			GenderizeURL:   os.Getenv("GENDERIZE_URL"),
-			NationalizeURL: os.Getenv("NATIONALIZE_URL"),
+			Providers: getEnvProviders("ENRICHERS", "agify,genderize,nationalize"),
+
+			EnrichTimeout:    getEnvDuration("ENRICH_TIMEOUT", 5*time.Second),
+			Timeout:          getEnvDuration("EXTERNAL_TIMEOUT", 2*time.Second),
+			RetryCount:       getEnvInt("EXTERNAL_RETRY_COUNT", 2),
+			RetryWaitTime:    getEnvDuration("EXTERNAL_RETRY_WAIT", 100*time.Millisecond),
+			RetryMaxWaitTime: getEnvDuration("EXTERNAL_RETRY_MAX_WAIT", 2*time.Second),
+
+			CacheSize: getEnvInt("ENRICH_CACHE_SIZE", 10000),
+			CacheTTL:  getEnvDuration("ENRICH_CACHE_TTL", 30*24*time.Hour),
+		},
+		Enrichment: Enrichment{
+			Workers:       getEnvInt("ENRICH_WORKERS", 4),
+			QueueSize:     getEnvInt("ENRICH_QUEUE_SIZE", 1000),
+			MaxAttempts:   getEnvInt("ENRICH_MAX_ATTEMPTS", 5),
+			RetryWait:     getEnvDuration("ENRICH_RETRY_WAIT", 5*time.Second),
+			RetryMaxWait:  getEnvDuration("ENRICH_RETRY_MAX_WAIT", 5*time.Minute),
+			SweepInterval: getEnvDuration("ENRICH_SWEEP_INTERVAL", time.Minute),
+
+			RefreshAge:      getEnvDuration("ENRICH_REFRESH_AGE", 90*24*time.Hour),
+			RefreshInterval: getEnvDuration("ENRICH_REFRESH_INTERVAL", time.Hour),
+			RefreshBatch:    getEnvInt("ENRICH_REFRESH_BATCH", 100),
+			Transliteration: getEnvString("ENRICH_TRANSLITERATION", "icao"),
+		},
+		Import: Import{
+			MaxBytes:  int64(getEnvInt("IMPORT_MAX_BYTES", 100<<20)),
+			ChunkSize: getEnvInt("IMPORT_CHUNK_SIZE", 500),
+			JobTTL:    getEnvDuration("IMPORT_JOB_TTL", 24*time.Hour),
+		},
+		Search: Search{
+			NationalityMinProbability: getEnvFloat("NATIONALITY_MIN_PROBABILITY", 0.05),
+			CountExactLimit:           getEnvInt("COUNT_EXACT_LIMIT", 10000),
+			SimilarityThreshold:       getEnvFloat("SEARCH_SIMILARITY_THRESHOLD", 0.3),
+			DuplicateThreshold:        getEnvFloat("DUPLICATE_SIMILARITY_THRESHOLD", 0.6),
+		},
+		Retention: Retention{
+			Deleted:       getEnvDuration("DELETED_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getEnvDuration("PURGE_INTERVAL", time.Hour),
+			PurgeBatch:    getEnvInt("PURGE_BATCH", 1000),
 		},
 	}
 }
+
+// getEnvProviders reads a comma-separated list of provider names;
+// the URL of each provider is taken from <NAME>_URL
+func getEnvProviders(key, def string) []enricher.Provider {
+	list := os.Getenv(key)
+	if list == "" {
+		list = def
+	}
+	var providers []enricher.Provider
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		providers = append(providers, enricher.Provider{
+			Name: name,
+			URL:  os.Getenv(strings.ToUpper(name) + "_URL"),
+		})
+	}
+	return providers
+}
+
+func getEnvString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+func getEnvFloat(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package apiserver
+
+import (
+	"effectiveMobile/internal/model"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// mergeHumansRequest represents the payload for merging duplicates
+// swagger:model
+type mergeHumansRequest struct {
+	// ID записи, которая остаётся
+	// required: true
+	SurvivorID int `json:"survivor_id" example:"1"`
+	// ID поглощаемых записей; их значения заполняют пустые поля в этом порядке
+	// required: true
+	MergedIDs []int `json:"merged_ids" example:"2,3"`
+}
+
+// duplicatesPage is a page of duplicate clusters
+// swagger:model
+type duplicatesPage struct {
+	Items []model.DuplicateCluster `json:"items"`
+	// значение after для следующей страницы; отсутствует, когда просмотрены все записи
+	NextAfter int `json:"next_after,omitempty" example:"1042"`
+}
+
+// getDuplicates returns clusters of probable duplicates
+// @Summary Find duplicates
+// @Description Group humans whose normalized full names are equal or similar by trigrams
+// @Description (at least DUPLICATE_SIMILARITY_THRESHOLD). A page examines up to page_size humans in ID order
+// @Description and clusters them with their most similar later humans; clusters with the most similar names come first.
+// @Description next_after of the response is passed as after to examine the following humans.
+// @Tags humans
+// @Produce json
+// @Param after query int false "Examine humans with a greater ID; next_after of the previous page"
+// @Param page_size query int false "Number of humans examined (default 1000, at most 5000)"
+// @Success 200 {object} duplicatesPage
+// @Failure 500 {object} problem
+// @Router /humans/duplicates [get]
+func (s *server) getDuplicates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		after, pageSize := 0, 1000
+		parseQueryInt(q, "after", &after)
+		parseQueryInt(q, "page_size", &pageSize)
+		if pageSize <= 0 || pageSize > 5000 {
+			pageSize = 1000
+		}
+
+		clusters, next, err := s.store.Human().FindDuplicates(r.Context(), s.config.Search.DuplicateThreshold, after, pageSize)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		page := duplicatesPage{Items: clusters, NextAfter: next}
+		if page.Items == nil {
+			page.Items = []model.DuplicateCluster{}
+		}
+		s.respond(w, http.StatusOK, page)
+	}
+}
+
+// mergeHumans merges duplicates into one record
+// @Summary Merge humans
+// @Description Merge records into the survivor in one transaction: empty fields of the survivor are filled
+// @Description from the merged records, which are then deleted. Snapshots of the merged records are kept in people_merges.
+// @Tags humans
+// @Accept json
+// @Produce json
+// @Param request body mergeHumansRequest true "Survivor and merged IDs"
+// @Success 200 {object} model.Human
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 415 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/merge [post]
+func (s *server) mergeHumans() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := mergeHumansRequest{}
+		if err := s.decodeJSON(r, &req); err != nil {
+			s.error(w, r, err)
+			return
+		}
+		if err := validateMerge(req); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		if err := s.store.Human().MergeHumans(r.Context(), req.SurvivorID, req.MergedIDs); err != nil {
+			s.error(w, r, err)
+			return
+		}
+		s.logger.Info("merged humans", zap.Int("survivor", req.SurvivorID), zap.Ints("merged", req.MergedIDs))
+		s.respondHuman(w, r, req.SurvivorID)
+	}
+}
+
+func validateMerge(req mergeHumansRequest) error {
+	var fields []fieldError
+	if req.SurvivorID < 1 {
+		fields = append(fields, fieldError{Field: "survivor_id", Code: "required", Message: "survivor_id is required"})
+	}
+	if len(req.MergedIDs) == 0 {
+		fields = append(fields, fieldError{Field: "merged_ids", Code: "required", Message: "merged_ids is required"})
+	}
+	seen := map[int]bool{req.SurvivorID: true}
+	for i, id := range req.MergedIDs {
+		if id < 1 || seen[id] {
+			fields = append(fields, fieldError{
+				Field:   fmt.Sprintf("merged_ids[%d]", i),
+				Code:    "invalid",
+				Message: "merged IDs must be positive, unique and differ from survivor_id",
+			})
+		}
+		seen[id] = true
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return errInvalidMerge.withFields(fields...)
+}
@@ -0,0 +1,175 @@
+package apiserver
+
+import (
+	"effectiveMobile/internal/filter"
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/store"
+	"effectiveMobile/internal/validate"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+const problemContentType = "application/problem+json"
+
+var (
+	errJSONDecode            = newAPIError(http.StatusBadRequest, "invalid_json", "error decoding request")
+	errValidationFailed      = newAPIError(http.StatusUnprocessableEntity, "validation_failed", "request is invalid")
+	errInvalidHumanID        = newAPIError(http.StatusBadRequest, "invalid_human_id", "invalid human id")
+	errUnsupportedMediaType  = newAPIError(http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/json")
+	errHumanNotFound         = newAPIError(http.StatusNotFound, "human_not_found", "human not found")
+	errNothingToUpdate       = newAPIError(http.StatusUnprocessableEntity, "nothing_to_update", "nothing to update")
+	errBatchTooLarge         = newAPIError(http.StatusRequestEntityTooLarge, "batch_too_large", "too many items in batch")
+	errImportJobNotFound     = newAPIError(http.StatusNotFound, "import_job_not_found", "import job not found")
+	errUnsupportedImportType = newAPIError(http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be text/csv or application/x-ndjson")
+	errRequestTooLarge       = newAPIError(http.StatusRequestEntityTooLarge, "request_too_large", "request body is too large")
+	errInvalidQuery          = newAPIError(http.StatusBadRequest, "invalid_query", "invalid query parameter")
+	errInvalidMerge          = newAPIError(http.StatusBadRequest, "validation_failed", "invalid merge request")
+	errInvalidFilter         = newAPIError(http.StatusBadRequest, "invalid_filter", "invalid filter expression")
+	errInvalidCursor         = newAPIError(http.StatusBadRequest, "invalid_cursor", "cursor is malformed or was issued for another sort")
+	errPreconditionFailed    = newAPIError(http.StatusPreconditionFailed, "precondition_failed", "If-Match does not match the current ETag of the human: it was changed or deleted since it was read")
+	errInvalidIfMatch        = newAPIError(http.StatusBadRequest, "invalid_if_match", "If-Match must hold a list of entity tags or *")
+	errUnsupportedPatchType  = newAPIError(http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/json, application/merge-patch+json or application/json-patch+json")
+	errInvalidPatch          = newAPIError(http.StatusBadRequest, "invalid_patch", "patch is malformed")
+	errPatchFailed           = newAPIError(http.StatusUnprocessableEntity, "patch_failed", "patch cannot be applied")
+	errPatchTestFailed       = newAPIError(http.StatusConflict, "patch_test_failed", "test operation of the patch failed")
+	errInvalidPatchedHuman   = newAPIError(http.StatusUnprocessableEntity, "validation_failed", "patched human is invalid")
+	errInternalServer        = newAPIError(http.StatusInternalServerError, "internal_error", "internal server error")
+)
+
+// apiError is an error that knows how it should be presented to the client
+type apiError struct {
+	Status int
+	Code   string
+	Detail string
+	Fields []fieldError
+}
+
+// fieldError describes a single invalid field of the request
+type fieldError struct {
+	Field   string `json:"field" example:"name"`
+	Code    string `json:"code" example:"required"`
+	Message string `json:"message" example:"name is required"`
+	// позиция символа в значении поля, начиная с 1
+	Position int `json:"position,omitempty" example:"17"`
+}
+
+// problem is an RFC 7807 problem details response
+// swagger:model
+type problem struct {
+	Type      string       `json:"type" example:"about:blank"`
+	Title     string       `json:"title" example:"Not Found"`
+	Status    int          `json:"status" example:"404"`
+	Code      string       `json:"code" example:"human_not_found"`
+	Detail    string       `json:"detail,omitempty" example:"human not found"`
+	Instance  string       `json:"instance,omitempty" example:"/humans/1"`
+	RequestID string       `json:"request_id,omitempty" example:"host/abcdef-000001"`
+	Errors    []fieldError `json:"errors,omitempty"`
+}
+
+func newAPIError(status int, code, detail string) *apiError {
+	return &apiError{Status: status, Code: code, Detail: detail}
+}
+
+func (e *apiError) Error() string {
+	return e.Detail
+}
+
+// withFields returns a copy of the error carrying per-field details
+func (e *apiError) withFields(fields ...fieldError) *apiError {
+	c := *e
+	c.Fields = append([]fieldError(nil), fields...)
+	return &c
+}
+
+// toAPIError maps an arbitrary error to its client representation
+func toAPIError(err error) *apiError {
+	var e *apiError
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.Is(err, store.ErrHumanNotFound):
+		return errHumanNotFound
+	case errors.Is(err, store.ErrNothingToUpdate):
+		return errNothingToUpdate
+	case errors.Is(err, store.ErrVersionConflict):
+		return errPreconditionFailed
+	case errors.Is(err, model.ErrInvalidCursor):
+		return errInvalidCursor
+	default:
+		return errInternalServer
+	}
+}
+
+// error writes err as an application/problem+json response
+func (s *server) error(w http.ResponseWriter, r *http.Request, err error) {
+	e := toAPIError(err)
+	requestID := middleware.GetReqID(r.Context())
+	if e.Status >= http.StatusInternalServerError {
+		s.logger.Error("request failed",
+			zap.Error(err),
+			zap.String("request_id", requestID),
+			zap.String("path", r.URL.Path),
+		)
+	}
+
+	p := problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Code:      e.Code,
+		Detail:    e.Detail,
+		Instance:  r.URL.Path,
+		RequestID: requestID,
+		Errors:    e.Fields,
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(e.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		s.logger.Error("error encoding response", zap.Error(err))
+	}
+}
+
+// requestIDHeader echoes the request ID back to the client
+func requestIDHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(middleware.RequestIDHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateRequest checks v against the rules of its validate tags
+// and returns the broken ones as a validation error, or nil
+func validateRequest(v any) error {
+	fields := validationFields(v)
+	if len(fields) == 0 {
+		return nil
+	}
+	return errValidationFailed.withFields(fields...)
+}
+
+func validationFields(v any) []fieldError {
+	var fields []fieldError
+	for _, e := range validate.Struct(v) {
+		fields = append(fields, fieldError{Field: e.Field, Code: e.Code, Message: e.Message})
+	}
+	return fields
+}
+
+// invalidQuery builds an error for a malformed query parameter
+func invalidQuery(param, message string) error {
+	return errInvalidQuery.withFields(fieldError{Field: param, Code: "invalid", Message: message})
+}
+
+// invalidFilter builds an error pointing at the position of a filter expression error
+func invalidFilter(err error) error {
+	var ferr *filter.Error
+	if !errors.As(err, &ferr) {
+		return err
+	}
+	return errInvalidFilter.withFields(fieldError{Field: "filter", Code: "syntax_error", Message: ferr.Msg, Position: ferr.Pos})
+}
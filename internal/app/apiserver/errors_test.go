@@ -0,0 +1,64 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// TestValidationProblem checks how broken validation rules reach the client as problem+json
+func TestValidationProblem(t *testing.T) {
+	s := &server{logger: zap.NewNop()}
+	req := &replaceHumanRequest{
+		Surname:     "Doe1",
+		Age:         200,
+		Gender:      "other",
+		Nationality: "Russia",
+	}
+	err := validateRequest(req)
+	if err == nil {
+		t.Fatal("validateRequest accepted an invalid request")
+	}
+
+	w := httptest.NewRecorder()
+	s.error(w, httptest.NewRequest(http.MethodPut, "/humans/1", nil), err)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != problemContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, problemContentType)
+	}
+	var p problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if p.Code != "validation_failed" || p.Instance != "/humans/1" {
+		t.Errorf("problem = %+v, want validation_failed for /humans/1", p)
+	}
+
+	var got [][2]string
+	for _, f := range p.Errors {
+		got = append(got, [2]string{f.Field, f.Code})
+	}
+	want := [][2]string{
+		{"name", "required"},
+		{"surname", "invalid_characters"},
+		{"age", "too_large"},
+		{"gender", "invalid_value"},
+		{"nationality", "invalid_country"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %v, want %v", got, want)
+	}
+}
+
+func TestValidRequest(t *testing.T) {
+	req := &addHumanRequest{Name: "Пётр", Surname: "O'Neil-Smith", Patronymic: "St. John"}
+	if err := validateRequest(req); err != nil {
+		t.Errorf("validateRequest = %v, want nil", err)
+	}
+}
@@ -0,0 +1,111 @@
+package apiserver
+
+import (
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/store"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// etag formats the version of a human as a strong entity tag
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// precondition is the If-Match header of a request; nil when the request has none
+type precondition struct {
+	// any is set by If-Match: *, which holds for every existing human
+	any bool
+	// versions are named by the strong tags of the header. Weak and foreign tags
+	// can never match a human and are left out.
+	versions []int
+}
+
+// ifMatch parses the If-Match header, a list of entity tags or *
+func ifMatch(r *http.Request) (*precondition, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" {
+		return nil, nil
+	}
+	if v == "*" {
+		return &precondition{any: true}, nil
+	}
+	p := &precondition{}
+	for _, tag := range strings.Split(v, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, errInvalidIfMatch
+		}
+		unquoted, ok := strings.CutPrefix(tag, `"`)
+		if !ok {
+			continue
+		}
+		if unquoted, ok = strings.CutSuffix(unquoted, `"`); !ok {
+			continue
+		}
+		if version, err := strconv.Atoi(unquoted); err == nil && version > 0 {
+			p.versions = append(p.versions, version)
+		}
+	}
+	return p, nil
+}
+
+// version returns the version the store has to find to make the change, 0 for any version.
+// current reads the version of the human; it is only called when the header lists several tags.
+func (p *precondition) version(current func() (int, error)) (int, error) {
+	switch {
+	case p == nil || p.any:
+		return 0, nil
+	case len(p.versions) == 0:
+		return 0, errPreconditionFailed
+	case len(p.versions) == 1:
+		return p.versions[0], nil
+	}
+	v, err := current()
+	if err != nil {
+		return 0, p.check(err)
+	}
+	if !slices.Contains(p.versions, v) {
+		return 0, errPreconditionFailed
+	}
+	return v, nil
+}
+
+// check maps a missing human to a failed precondition: If-Match never holds without a current representation
+func (p *precondition) check(err error) error {
+	if p != nil && errors.Is(err, store.ErrHumanNotFound) {
+		return errPreconditionFailed
+	}
+	return err
+}
+
+// setHumanValidators sets the headers clients use in conditional requests for the human
+func setHumanValidators(w http.ResponseWriter, human *model.Human) {
+	w.Header().Set("ETag", etag(human.Version))
+	if !human.UpdatedAt.IsZero() {
+		w.Header().Set("Last-Modified", human.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified reports whether the client copy of the human is current. If-None-Match
+// takes precedence over If-Modified-Since, which has a precision of one second.
+func notModified(r *http.Request, human *model.Human) bool {
+	if v := r.Header.Get("If-None-Match"); v != "" {
+		for _, tag := range strings.Split(v, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag(human.Version) {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !human.UpdatedAt.Truncate(time.Second).After(since)
+}
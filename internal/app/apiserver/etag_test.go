@@ -0,0 +1,149 @@
+package apiserver
+
+import (
+	"context"
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/store"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   *precondition
+		err    error
+	}{
+		{"", nil, nil},
+		{"*", &precondition{any: true}, nil},
+		{" * ", &precondition{any: true}, nil},
+		{`"3"`, &precondition{versions: []int{3}}, nil},
+		{`"3", "4"`, &precondition{versions: []int{3, 4}}, nil},
+		{`"3",,"4",`, &precondition{versions: []int{3, 4}}, nil},
+
+		// слабые теги и теги других ресурсов не совпадают ни с какой версией
+		{`W/"3"`, &precondition{}, nil},
+		{`W/"3", "4"`, &precondition{versions: []int{4}}, nil},
+		{`"abc"`, &precondition{}, nil},
+		{`"0", "-1", "3`, &precondition{}, nil},
+		{`3`, &precondition{}, nil},
+
+		{`"3", *`, nil, errInvalidIfMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/humans/1", nil)
+			r.Header.Set("If-Match", tt.header)
+			got, err := ifMatch(r)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ifMatch error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ifMatch = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreconditionVersion(t *testing.T) {
+	current := func(v int) func() (int, error) {
+		return func() (int, error) { return v, nil }
+	}
+	notFound := func() (int, error) { return 0, store.ErrHumanNotFound }
+	unread := func() (int, error) {
+		t.Error("current version read for a header with at most one tag")
+		return 0, nil
+	}
+
+	tests := []struct {
+		name    string
+		p       *precondition
+		current func() (int, error)
+		want    int
+		err     error
+	}{
+		{"no header", nil, unread, 0, nil},
+		{"*", &precondition{any: true}, unread, 0, nil},
+		{"one tag", &precondition{versions: []int{3}}, unread, 3, nil},
+		{"weak or foreign tags only", &precondition{}, unread, 0, errPreconditionFailed},
+		{"list with the current version", &precondition{versions: []int{3, 4}}, current(4), 4, nil},
+		{"list without the current version", &precondition{versions: []int{3, 4}}, current(5), 0, errPreconditionFailed},
+		{"list for a missing human", &precondition{versions: []int{3, 4}}, notFound, 0, errPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.p.version(tt.current)
+			if got != tt.want || !errors.Is(err, tt.err) {
+				t.Errorf("version = %d, %v, want %d, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+// missingHumans is a repository without humans; methods the tests do not call panic
+type missingHumans struct {
+	store.HumanRepository
+}
+
+func (missingHumans) GetHumans(context.Context, *model.HumanFilter) ([]model.Human, error) {
+	return nil, nil
+}
+
+func (missingHumans) DeleteHuman(context.Context, int, int) error {
+	return store.ErrHumanNotFound
+}
+
+func (missingHumans) RestoreHuman(context.Context, int, int) error {
+	return store.ErrHumanNotFound
+}
+
+type missingStore struct {
+	store.Store
+}
+
+func (missingStore) Human() store.HumanRepository {
+	return missingHumans{}
+}
+
+// TestIfMatchMissingHuman checks that a conditional change of a missing human fails its precondition
+func TestIfMatchMissingHuman(t *testing.T) {
+	s := &server{logger: zap.NewNop(), store: missingStore{}}
+	router := chi.NewRouter()
+	router.Delete("/humans/{id}", s.deleteHumanByID())
+	router.Post("/humans/{id}/restore", s.restoreHuman())
+
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"", http.StatusNotFound},
+		{"*", http.StatusPreconditionFailed},
+		{`"3"`, http.StatusPreconditionFailed},
+		{`"3", "4"`, http.StatusPreconditionFailed},
+		{`W/"3"`, http.StatusPreconditionFailed},
+		{`"3", *`, http.StatusBadRequest},
+	}
+	for _, route := range []struct{ method, path string }{
+		{http.MethodDelete, "/humans/7"},
+		{http.MethodPost, "/humans/7/restore"},
+	} {
+		for _, tt := range tests {
+			t.Run(route.method+" "+tt.header, func(t *testing.T) {
+				r := httptest.NewRequest(route.method, route.path, nil)
+				if tt.header != "" {
+					r.Header.Set("If-Match", tt.header)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+				if w.Code != tt.want {
+					t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package apiserver
+
+import (
+	"bufio"
+	"effectiveMobile/internal/app/xlsx"
+	"effectiveMobile/internal/model"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+	exportFormatXLSX   = "xlsx"
+
+	// exportFlushRows задаёт, через сколько строк накопленные данные отправляются клиенту
+	exportFlushRows = 1000
+)
+
+var exportColumns = []string{
+	"id", "name", "surname", "patronymic", "age", "gender", "nationality", "enrichment_status", "enriched_at",
+	"created_at", "updated_at",
+}
+
+// humanEncoder writes exported humans in one of the export formats
+type humanEncoder interface {
+	encode(h *model.Human) error
+	flush() error
+	// close writes the trailing part of the format and flushes
+	close() error
+}
+
+// exportHumans streams every human matching the filter as a file
+// @Summary Export humans
+// @Description Stream all humans matching the filter as CSV, NDJSON or XLSX. Accepts the filters
+// @Description of GET /humans; pagination is ignored. Nationality candidates and enrichment sources are not exported.
+// @Tags humans
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, ndjson, xlsx) default(csv)
+// @Param name query string false "Name filter"
+// @Param surname query string false "Surname filter"
+// @Param patronymic query string false "Patronymic filter"
+// @Param gender query string false "Gender filter"
+// @Param nationality query string false "Nationality filter"
+// @Param nationality_any query string false "Comma-separated countries matched against every nationality candidate, e.g. RU,UA"
+// @Param nationality_min_probability query number false "Minimum candidate probability for nationality_any"
+// @Param enrichment_status query string false "Enrichment status filter" Enums(pending, enriched, failed)
+// @Param min_age query int false "Minimum age filter"
+// @Param max_age query int false "Maximum age filter"
+// @Param filter query string false "Filter expression combined with the other filters by AND" example(nationality in ('RU','KZ') and age >= 18)
+// @Param include_deleted query bool false "Also export deleted humans that are not purged yet"
+// @Param created_after query string false "Only humans created after this RFC 3339 time" example(2025-05-25T00:00:00Z)
+// @Param created_before query string false "Only humans created before this RFC 3339 time"
+// @Param updated_since query string false "Only humans changed at or after this RFC 3339 time" example(2025-05-25T00:00:00Z)
+// @Param updated_before query string false "Only humans last changed before this RFC 3339 time"
+// @Success 200 {file} file
+// @Header 200 {string} Content-Disposition "attachment; filename=humans.csv"
+// @Failure 400 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/export [get]
+func (s *server) exportHumans() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		format := q.Get("format")
+		if format == "" {
+			format = exportFormatCSV
+		}
+		f, err := s.humanFilter(q)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		out := &exportWriter{w: w}
+		enc, contentType, err := newHumanEncoder(format, out)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="humans.%s"`, format))
+
+		rc := http.NewResponseController(w)
+		rows := 0
+		err = s.store.Human().StreamHumans(r.Context(), f, func(h *model.Human) error {
+			if err := enc.encode(h); err != nil {
+				return err
+			}
+			rows++
+			if rows%exportFlushRows != 0 {
+				return nil
+			}
+			if err := enc.flush(); err != nil {
+				return err
+			}
+			return rc.Flush()
+		})
+		if err == nil {
+			err = enc.close()
+		}
+
+		switch {
+		case err == nil:
+			s.logger.Info("export completed", zap.String("format", format), zap.Int("rows", rows))
+		case r.Context().Err() != nil:
+			// Клиент отключился, запрос к базе уже отменён через контекст
+			s.logger.Info("export cancelled", zap.String("format", format), zap.Int("rows", rows))
+		case !out.written:
+			w.Header().Del("Content-Disposition")
+			s.error(w, r, err)
+		default:
+			// Часть файла уже отправлена: обрываем соединение, чтобы клиент не принял его за полный
+			s.logger.Error("export failed", zap.String("format", format), zap.Int("rows", rows), zap.Error(err))
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// exportWriter remembers whether anything has been sent to the client
+type exportWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	e.written = true
+	return e.w.Write(p)
+}
+
+func newHumanEncoder(format string, w io.Writer) (humanEncoder, string, error) {
+	switch format {
+	case exportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportColumns); err != nil {
+			return nil, "", err
+		}
+		return &csvEncoder{cw: cw, record: make([]string, len(exportColumns))}, "text/csv; charset=utf-8", nil
+	case exportFormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonEncoder{bw: bw, enc: json.NewEncoder(bw)}, importFormatNDJSON, nil
+	case exportFormatXLSX:
+		xw, err := xlsx.NewWriter(w, "humans")
+		if err != nil {
+			return nil, "", err
+		}
+		header := make([]interface{}, len(exportColumns))
+		for i, c := range exportColumns {
+			header[i] = c
+		}
+		if err := xw.WriteRow(header...); err != nil {
+			return nil, "", err
+		}
+		return &xlsxEncoder{xw: xw}, xlsx.ContentType, nil
+	default:
+		return nil, "", invalidQuery("format", "format must be csv, ndjson or xlsx")
+	}
+}
+
+type csvEncoder struct {
+	cw     *csv.Writer
+	record []string
+}
+
+func (e *csvEncoder) encode(h *model.Human) error {
+	e.record[0] = strconv.Itoa(h.Id)
+	e.record[1] = h.Name
+	e.record[2] = h.Surname
+	e.record[3] = h.Patronymic
+	e.record[4] = strconv.Itoa(h.Age)
+	e.record[5] = h.Gender
+	e.record[6] = h.Nationality
+	e.record[7] = h.EnrichmentStatus
+	e.record[8] = ""
+	if h.EnrichedAt != nil {
+		e.record[8] = h.EnrichedAt.Format(time.RFC3339)
+	}
+	e.record[9] = h.CreatedAt.Format(time.RFC3339)
+	e.record[10] = h.UpdatedAt.Format(time.RFC3339)
+	return e.cw.Write(e.record)
+}
+
+func (e *csvEncoder) flush() error {
+	e.cw.Flush()
+	return e.cw.Error()
+}
+
+func (e *csvEncoder) close() error {
+	return e.flush()
+}
+
+type ndjsonEncoder struct {
+	bw  *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) encode(h *model.Human) error {
+	return e.enc.Encode(h)
+}
+
+func (e *ndjsonEncoder) flush() error {
+	return e.bw.Flush()
+}
+
+func (e *ndjsonEncoder) close() error {
+	return e.bw.Flush()
+}
+
+type xlsxEncoder struct {
+	xw *xlsx.Writer
+}
+
+func (e *xlsxEncoder) encode(h *model.Human) error {
+	var enrichedAt interface{}
+	if h.EnrichedAt != nil {
+		enrichedAt = h.EnrichedAt.Format(time.RFC3339)
+	}
+	return e.xw.WriteRow(h.Id, h.Name, h.Surname, h.Patronymic, h.Age, h.Gender, h.Nationality, h.EnrichmentStatus, enrichedAt,
+		h.CreatedAt.Format(time.RFC3339), h.UpdatedAt.Format(time.RFC3339))
+}
+
+func (e *xlsxEncoder) flush() error {
+	return e.xw.Flush()
+}
+
+func (e *xlsxEncoder) close() error {
+	return e.xw.Close()
+}
@@ -0,0 +1,37 @@
+package apiserver
+
+import (
+	"effectiveMobile/internal/model"
+	"net/http"
+)
+
+// getHumanHistory returns the timeline of changes of a human
+// @Summary Get human history
+// @Description List every change of a human, oldest first: who made it (X-Actor header of the request),
+// @Description the request ID and the old and new values of the changed fields. The history of a deleted human stays available.
+// @Tags humans
+// @Produce json
+// @Param id path int true "Human ID"
+// @Success 200 {array} model.HistoryEntry
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/{id}/history [get]
+func (s *server) getHumanHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := humanID(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		entries, err := s.store.Human().GetHistory(r.Context(), id)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		if entries == nil {
+			entries = []model.HistoryEntry{}
+		}
+		s.respond(w, http.StatusOK, entries)
+	}
+}
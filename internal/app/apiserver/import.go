@@ -0,0 +1,375 @@
+package apiserver
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/store"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	importFormatCSV    = "text/csv"
+	importFormatNDJSON = "application/x-ndjson"
+
+	importRunning   = "running"
+	importCompleted = "completed"
+	importFailed    = "failed"
+
+	// maxImportRowErrors ограничивает количество хранимых ошибок строк одного импорта
+	maxImportRowErrors = 1000
+	maxNDJSONLine      = 1 << 20
+)
+
+// importRowError describes a rejected row of an import
+// swagger:model
+type importRowError struct {
+	// номер строки данных, начиная с 1
+	Row    int          `json:"row" example:"3"`
+	Errors []fieldError `json:"errors"`
+}
+
+// importJobStatus represents the progress of an import
+// swagger:model
+type importJobStatus struct {
+	ID     string `json:"id" example:"5f2b8c0e9a7d4e1f8b3c6a2d1e0f9a8b"`
+	Status string `json:"status" example:"running"`
+	Format string `json:"format" example:"text/csv"`
+	// обработано строк
+	Processed int `json:"processed" example:"1500"`
+	Created   int `json:"created" example:"1498"`
+	Rejected  int `json:"rejected" example:"2"`
+	// ошибки строк; хранятся первые 1000
+	Errors []importRowError `json:"errors,omitempty"`
+	// причина аварийного завершения
+	Error      string     `json:"error,omitempty" example:""`
+	StartedAt  time.Time  `json:"started_at" example:"2025-05-25T12:00:00Z"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" example:"2025-05-25T12:01:00Z"`
+}
+
+type importJob struct {
+	mu     sync.Mutex
+	status importJobStatus
+}
+
+func (j *importJob) snapshot() importJobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	st := j.status
+	st.Errors = append([]importRowError(nil), j.status.Errors...)
+	return st
+}
+
+func (j *importJob) update(fn func(st *importJobStatus)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(&j.status)
+}
+
+// importJobs keeps import progress in memory; finished jobs expire after ttl
+type importJobs struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	jobs map[string]*importJob
+}
+
+func newImportJobs(ttl time.Duration) *importJobs {
+	return &importJobs{
+		ttl:  ttl,
+		jobs: make(map[string]*importJob),
+	}
+}
+
+func (r *importJobs) create(format string) *importJob {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	job := &importJob{status: importJobStatus{
+		ID:        hex.EncodeToString(buf),
+		Status:    importRunning,
+		Format:    format,
+		StartedAt: time.Now(),
+	}}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire()
+	r.jobs[job.status.ID] = job
+	return job
+}
+
+func (r *importJobs) get(id string) (*importJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire()
+	job, ok := r.jobs[id]
+	return job, ok
+}
+
+// expire drops finished jobs older than ttl; r.mu must be held
+func (r *importJobs) expire() {
+	for id, job := range r.jobs {
+		st := job.snapshot()
+		if st.FinishedAt != nil && time.Since(*st.FinishedAt) > r.ttl {
+			delete(r.jobs, id)
+		}
+	}
+}
+
+// importHumans starts an import of humans from a CSV or NDJSON upload
+// @Summary Import humans
+// @Description Upload humans as CSV (header with name, surname, patronymic columns) or NDJSON
+// @Description (one addHumanRequest object per line). Rows are validated like POST /humans,
+// @Description inserted in chunks and enriched in the background. Poll the returned job for progress.
+// @Tags humans
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Success 202 {object} importJobStatus
+// @Header 202 {string} Location "URL of the import job"
+// @Failure 413 {object} problem
+// @Failure 415 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/import [post]
+func (s *server) importHumans() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || (format != importFormatCSV && format != importFormatNDJSON) {
+			s.error(w, r, errUnsupportedImportType)
+			return
+		}
+
+		// Тело сохраняется во временный файл, чтобы обработка могла продолжаться после ответа
+		file, err := os.CreateTemp("", "humans-import-*")
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		body := http.MaxBytesReader(w, r.Body, s.config.Import.MaxBytes)
+		if _, err := io.Copy(file, body); err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				s.error(w, r, errRequestTooLarge)
+				return
+			}
+			s.error(w, r, err)
+			return
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			s.error(w, r, err)
+			return
+		}
+
+		job := s.imports.create(format)
+		ctx := store.WithAudit(s.jobsCtx, store.AuditFrom(r.Context()))
+		s.jobs.Add(1)
+		go func() {
+			defer s.jobs.Done()
+			defer os.Remove(file.Name())
+			defer file.Close()
+			s.runImport(ctx, job, format, file)
+		}()
+		s.logger.Info("import started", zap.String("job", job.status.ID), zap.String("format", format))
+
+		w.Header().Set("Location", "/humans/import/"+job.status.ID)
+		s.respond(w, http.StatusAccepted, job.snapshot())
+	}
+}
+
+// getImportJob returns the progress of an import
+// @Summary Get import job
+// @Description Progress and per-row errors of an import started with POST /humans/import
+// @Tags humans
+// @Produce json
+// @Param jobID path string true "Import job ID"
+// @Success 200 {object} importJobStatus
+// @Failure 404 {object} problem
+// @Router /humans/import/{jobID} [get]
+func (s *server) getImportJob() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, ok := s.imports.get(chi.URLParam(r, "jobID"))
+		if !ok {
+			s.error(w, r, errImportJobNotFound)
+			return
+		}
+		s.respond(w, http.StatusOK, job.snapshot())
+	}
+}
+
+// importRow is a parsed row of an import; err is set for malformed rows
+type importRow struct {
+	req addHumanRequest
+	err *fieldError
+}
+
+func (s *server) runImport(ctx context.Context, job *importJob, format string, r io.Reader) {
+	var (
+		humans []model.Human
+		err    error
+		row    int
+	)
+	flush := func() error {
+		if len(humans) == 0 {
+			return nil
+		}
+		if err := s.store.Human().AddHumans(ctx, humans); err != nil {
+			return err
+		}
+		s.pipeline.EnqueueBatch(humans)
+		n := len(humans)
+		job.update(func(st *importJobStatus) { st.Created += n })
+		humans = nil
+		return nil
+	}
+	handle := func(ir importRow) error {
+		row++
+		trimNames(&ir.req.Name, &ir.req.Surname, &ir.req.Patronymic)
+		var fields []fieldError
+		if ir.err != nil {
+			fields = []fieldError{*ir.err}
+		} else {
+			fields = validationFields(&ir.req)
+		}
+		job.update(func(st *importJobStatus) {
+			st.Processed++
+			if fields != nil {
+				st.Rejected++
+				if len(st.Errors) < maxImportRowErrors {
+					st.Errors = append(st.Errors, importRowError{Row: row, Errors: fields})
+				}
+			}
+		})
+		if fields != nil {
+			return nil
+		}
+		humans = append(humans, model.Human{
+			Name:             ir.req.Name,
+			Surname:          ir.req.Surname,
+			Patronymic:       ir.req.Patronymic,
+			Gender:           "unknown",
+			EnrichmentStatus: model.EnrichmentPending,
+		})
+		if len(humans) >= s.config.Import.ChunkSize {
+			return flush()
+		}
+		return ctx.Err()
+	}
+
+	switch format {
+	case importFormatCSV:
+		err = readCSV(r, handle)
+	case importFormatNDJSON:
+		err = readNDJSON(r, handle)
+	}
+	if err == nil {
+		err = flush()
+	}
+
+	now := time.Now()
+	job.update(func(st *importJobStatus) {
+		st.FinishedAt = &now
+		st.Status = importCompleted
+		if err != nil {
+			st.Status = importFailed
+			st.Error = err.Error()
+		}
+	})
+	st := job.snapshot()
+	if err != nil {
+		s.logger.Error("import failed", zap.String("job", st.ID), zap.Error(err))
+		return
+	}
+	s.logger.Info("import completed",
+		zap.String("job", st.ID), zap.Int("created", st.Created), zap.Int("rejected", st.Rejected))
+}
+
+// readCSV calls handle for every data row of a CSV with a header
+func readCSV(r io.Reader, handle func(importRow) error) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("read csv header: %w", err)
+	}
+	columns := map[string]int{"name": -1, "surname": -1, "patronymic": -1}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if _, ok := columns[h]; ok {
+			columns[h] = i
+		}
+	}
+	if columns["name"] < 0 || columns["surname"] < 0 {
+		return errors.New("csv header must contain name and surname columns")
+	}
+	field := func(record []string, column string) string {
+		if i := columns[column]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if err := handle(importRow{err: &fieldError{Field: "row", Code: "invalid_csv", Message: parseErr.Err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		ir := importRow{req: addHumanRequest{
+			Name:       field(record, "name"),
+			Surname:    field(record, "surname"),
+			Patronymic: field(record, "patronymic"),
+		}}
+		if err := handle(ir); err != nil {
+			return err
+		}
+	}
+}
+
+// readNDJSON calls handle for every non-empty line of an NDJSON stream
+func readNDJSON(r io.Reader, handle func(importRow) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxNDJSONLine)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var ir importRow
+		if err := json.Unmarshal([]byte(line), &ir.req); err != nil {
+			ir.err = &fieldError{Field: "row", Code: "invalid_json", Message: err.Error()}
+		}
+		if err := handle(ir); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -1,17 +1,22 @@
 package apiserver
 
+import (
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/normalize"
+)
+
 // addHumanRequest represents the payload for adding a human
 // swagger:model
 type addHumanRequest struct {
 	// имя
 	// required: true
-	Name string `json:"name" example:"John"`
+	Name string `json:"name" example:"John" validate:"required,max=255,name"`
 	// фамилия
 	// required: true
-	Surname string `json:"surname" example:"Doe"`
+	Surname string `json:"surname" example:"Doe" validate:"required,max=255,name"`
 	// отчество
 	// required: false
-	Patronymic string `json:"patronymic" example:"Johnny"`
+	Patronymic string `json:"patronymic" example:"Johnny" validate:"max=255,name"`
 }
 
 // deleteHumanRequest represents the payload for deleting a human
@@ -27,23 +32,130 @@ type deleteHumanRequest struct {
 type updateHumanRequest struct {
 	// ID человека
 	// required: true
-	ID int `json:"id" example:"1"`
+	ID int `json:"id" example:"1" validate:"required"`
 	// имя
 	// required: false
-	Name string `json:"name" example:"John"`
+	Name string `json:"name" example:"John" validate:"max=255,name"`
 	// фамилия
 	// required: false
-	Surname string `json:"surname" example:"Doe"`
+	Surname string `json:"surname" example:"Doe" validate:"max=255,name"`
 	// отчество
 	// required: false
-	Patronymic string `json:"patronymic" example:"Johnny"`
+	Patronymic string `json:"patronymic" example:"Johnny" validate:"max=255,name"`
 	// возраст
 	// required: false
-	Age int `json:"age" example:"30"`
+	Age int `json:"age" example:"30" validate:"min=0,max=150"`
 	// пол
 	// required: false
-	Gender string `json:"gender" example:"male"`
+	Gender string `json:"gender" example:"male" validate:"oneof=male female unknown"`
 	// национальность
 	// required: false
-	Nationality string `json:"nationality" example:"RU"`
+	Nationality string `json:"nationality" example:"RU" validate:"iso3166"`
+}
+
+// patchHumanRequest represents the payload for partially updating a human by ID
+// swagger:model
+type patchHumanRequest struct {
+	// имя
+	// required: false
+	Name string `json:"name" example:"John" validate:"max=255,name"`
+	// фамилия
+	// required: false
+	Surname string `json:"surname" example:"Doe" validate:"max=255,name"`
+	// отчество
+	// required: false
+	Patronymic string `json:"patronymic" example:"Johnny" validate:"max=255,name"`
+	// возраст
+	// required: false
+	Age int `json:"age" example:"30" validate:"min=0,max=150"`
+	// пол
+	// required: false
+	Gender string `json:"gender" example:"male" validate:"oneof=male female unknown"`
+	// национальность
+	// required: false
+	Nationality string `json:"nationality" example:"RU" validate:"iso3166"`
+}
+
+// replaceHumanRequest represents the payload for replacing a human by ID
+// swagger:model
+type replaceHumanRequest struct {
+	// имя
+	// required: true
+	Name string `json:"name" example:"John" validate:"required,max=255,name"`
+	// фамилия
+	// required: true
+	Surname string `json:"surname" example:"Doe" validate:"required,max=255,name"`
+	// отчество
+	// required: false
+	Patronymic string `json:"patronymic" example:"Johnny" validate:"max=255,name"`
+	// возраст
+	// required: false
+	Age int `json:"age" example:"30" validate:"min=0,max=150"`
+	// пол
+	// required: false
+	Gender string `json:"gender" example:"male" validate:"oneof=male female unknown"`
+	// национальность
+	// required: false
+	Nationality string `json:"nationality" example:"RU" validate:"iso3166"`
+}
+
+// invalidateCacheResponse reports how many cached entries were removed
+// swagger:model
+type invalidateCacheResponse struct {
+	// количество удалённых записей в Postgres
+	Deleted int64 `json:"deleted" example:"3"`
+}
+
+// enrichResponse reports how many humans were queued for enrichment
+// swagger:model
+type enrichResponse struct {
+	// количество записей, поставленных в очередь
+	Queued int `json:"queued" example:"42"`
+	// подходящие записи остались: повторите запрос, чтобы поставить в очередь следующую порцию
+	More bool `json:"more" example:"false"`
+}
+
+// batchItemResult is the outcome of creating one item of a batch
+// swagger:model
+type batchItemResult struct {
+	// позиция элемента в запросе
+	Index int `json:"index" example:"0"`
+	// ID созданной записи
+	ID int `json:"id,omitempty" example:"1"`
+	// URL созданной записи
+	Location string `json:"location,omitempty" example:"/humans/1"`
+	// accepted или invalid
+	Status string `json:"status" example:"accepted"`
+	// ошибки валидации элемента
+	Errors []fieldError `json:"errors,omitempty"`
+}
+
+// batchResponse represents the result of a bulk creation
+// swagger:model
+type batchResponse struct {
+	Accepted int               `json:"accepted" example:"2"`
+	Rejected int               `json:"rejected" example:"1"`
+	Items    []batchItemResult `json:"items"`
+}
+
+// humansPage is a page of humans with the number of matches
+// swagger:model
+type humansPage struct {
+	Items []model.Human `json:"items"`
+	// номер страницы; отсутствует в режиме cursor
+	Page     int `json:"page,omitempty" example:"1"`
+	PageSize int `json:"page_size" example:"20"`
+	// количество записей, подходящих под фильтр
+	Total int64 `json:"total" example:"1342"`
+	// total — оценка планировщика, а не точный подсчёт
+	TotalEstimated bool `json:"total_estimated" example:"false"`
+	// токен следующей страницы в режиме cursor; отсутствует на последней странице
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiLWlkIiwidiI6WyI0MiJdfQ"`
+}
+
+// trimNames normalizes Unicode and whitespace of the name parts in place
+func trimNames(parts ...*string) {
+	for _, p := range parts {
+		*p = normalize.Trim(*p)
+	}
 }
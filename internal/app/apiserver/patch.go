@@ -0,0 +1,203 @@
+package apiserver
+
+import (
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/normalize"
+	"effectiveMobile/internal/patch"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"sort"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// nonEmptyPatch builds the changes of a plain JSON update, where empty fields are kept
+func nonEmptyPatch(h *model.Human) *model.HumanPatch {
+	p := &model.HumanPatch{}
+	if h.Name != "" {
+		p.Name = &h.Name
+	}
+	if h.Surname != "" {
+		p.Surname = &h.Surname
+	}
+	if h.Patronymic != "" {
+		p.Patronymic = &h.Patronymic
+	}
+	if h.Age > 0 {
+		p.Age = &h.Age
+	}
+	if h.Gender != "" {
+		p.Gender = &h.Gender
+	}
+	if h.Nationality != "" {
+		p.Nationality = &h.Nationality
+	}
+	return p
+}
+
+// humanDocument is the JSON document patches of a human are applied to: its editable fields
+// as GET /humans/{id} returns them, empty ones included, so that they can be replaced and tested
+func humanDocument(h *model.Human) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        h.Name,
+		"surname":     h.Surname,
+		"patronymic":  h.Patronymic,
+		"age":         float64(h.Age),
+		"gender":      h.Gender,
+		"nationality": h.Nationality,
+	}
+}
+
+// patchDocument applies a merge patch or a JSON Patch from the request body to the document of current
+// and returns the changes it makes, or nil when it changes nothing
+func (s *server) patchDocument(r *http.Request, current *model.Human, mediaType string) (*model.HumanPatch, error) {
+	var doc interface{} = humanDocument(current)
+	switch mediaType {
+	case mergePatchContentType:
+		var p interface{}
+		if err := s.decodeBody(r, &p); err != nil {
+			return nil, err
+		}
+		doc = patch.Merge(doc, p)
+	case jsonPatchContentType:
+		var ops []patch.Operation
+		if err := s.decodeBody(r, &ops); err != nil {
+			return nil, err
+		}
+		var err error
+		if doc, err = patch.Apply(doc, ops); err != nil {
+			return nil, patchFailed(err)
+		}
+	}
+	return diffHuman(current, doc)
+}
+
+// patchFailed maps an error of patch.Apply to its client representation
+func patchFailed(err error) error {
+	var e *patch.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	if errors.Is(err, patch.ErrMissingValue) {
+		return newAPIError(http.StatusBadRequest, errInvalidPatch.Code, e.Error())
+	}
+	if errors.Is(err, patch.ErrTestFailed) {
+		return newAPIError(http.StatusConflict, errPatchTestFailed.Code, e.Error())
+	}
+	return newAPIError(http.StatusUnprocessableEntity, errPatchFailed.Code, e.Error())
+}
+
+// diffHuman validates the patched document and returns its differences from current.
+// A missing, null or empty field is cleared; a cleared gender becomes unknown.
+func diffHuman(current *model.Human, doc interface{}) (*model.HumanPatch, error) {
+	fields, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errInvalidPatchedHuman.withFields(fieldError{
+			Field: "", Code: "invalid_type", Message: "patched document must be an object",
+		})
+	}
+
+	var (
+		errs   []fieldError
+		result model.Human
+	)
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := fields[k]
+		var dest *string
+		switch k {
+		case "name":
+			dest = &result.Name
+		case "surname":
+			dest = &result.Surname
+		case "patronymic":
+			dest = &result.Patronymic
+		case "gender":
+			dest = &result.Gender
+		case "nationality":
+			dest = &result.Nationality
+		case "age":
+			if v == nil {
+				continue
+			}
+			n, ok := v.(float64)
+			if !ok || n != math.Trunc(n) || math.Abs(n) > math.MaxInt32 {
+				errs = append(errs, fieldError{Field: k, Code: "invalid_type", Message: "age must be an integer"})
+				continue
+			}
+			result.Age = int(n)
+			continue
+		default:
+			errs = append(errs, fieldError{Field: k, Code: "unknown_field", Message: k + " cannot be changed"})
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		str, ok := v.(string)
+		if !ok {
+			errs = append(errs, fieldError{Field: k, Code: "invalid_type", Message: k + " must be a string"})
+			continue
+		}
+		*dest = normalize.Trim(str)
+	}
+	if result.Gender == "" && current.Gender != "" {
+		result.Gender = "unknown"
+	}
+	errs = append(errs, validationFields(&replaceHumanRequest{
+		Name:        result.Name,
+		Surname:     result.Surname,
+		Patronymic:  result.Patronymic,
+		Age:         result.Age,
+		Gender:      result.Gender,
+		Nationality: result.Nationality,
+	})...)
+	if len(errs) > 0 {
+		return nil, errInvalidPatchedHuman.withFields(errs...)
+	}
+
+	p := &model.HumanPatch{}
+	changed := false
+	for _, f := range []struct {
+		cur, next *string
+		dest      **string
+	}{
+		{&current.Name, &result.Name, &p.Name},
+		{&current.Surname, &result.Surname, &p.Surname},
+		{&current.Patronymic, &result.Patronymic, &p.Patronymic},
+		{&current.Gender, &result.Gender, &p.Gender},
+		{&current.Nationality, &result.Nationality, &p.Nationality},
+	} {
+		if *f.cur != *f.next {
+			*f.dest = f.next
+			changed = true
+		}
+	}
+	if current.Age != result.Age {
+		p.Age = &result.Age
+		changed = true
+	}
+	if !changed {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// decodeBody decodes the JSON request body into v without checking its media type
+func (s *server) decodeBody(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		s.logger.Info("error decoding request", zap.Error(err))
+		return errJSONDecode
+	}
+	return nil
+}
@@ -9,38 +9,44 @@ package apiserver
 import (
 	"context"
 	_ "effectiveMobile/docs"
-	"effectiveMobile/internal/app/client/agify"
-	"effectiveMobile/internal/app/client/genderize"
-	"effectiveMobile/internal/app/client/nationalize"
+	"effectiveMobile/internal/app/client"
+	"effectiveMobile/internal/app/enricher"
+	"effectiveMobile/internal/app/pipeline"
+	"effectiveMobile/internal/filter"
 	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/normalize"
 	"effectiveMobile/internal/store"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"golang.org/x/sync/errgroup"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
-const (
-	ErrJsonDecodeError        = "error decoding request"
-	ErrNameAndSurnameRequired = "name and surname required"
-	ErrInternalServer         = "internal server error"
-	//ErrUnsupportedMediaType   = "unsupported media type"
-)
+// searchKeyBatch — сколько записей получают ключ поиска за один запрос при фоновом заполнении
+const searchKeyBatch = 1000
 
 type server struct {
-	router      chi.Router
-	config      *Config
-	logger      *zap.Logger
-	store       store.Store
-	agify       *agify.Agify
-	genderize   *genderize.Genderize
-	nationalize *nationalize.Nationalize
+	router   chi.Router
+	config   *Config
+	logger   *zap.Logger
+	store    store.Store
+	cache    *enricher.Cache
+	pipeline *pipeline.Pipeline
+	imports  *importJobs
+
+	// jobsCtx отменяется при остановке процесса, jobs отслеживает фоновые задачи
+	jobsCtx context.Context
+	jobs    sync.WaitGroup
 }
 
 func newServer(store store.Store, config *Config) *server {
@@ -53,162 +59,273 @@ func newServer(store store.Store, config *Config) *server {
 	if err != nil {
 		panic(err)
 	}
+	opts := client.Options{
+		Timeout:          config.ExternalService.Timeout,
+		RetryCount:       config.ExternalService.RetryCount,
+		RetryWaitTime:    config.ExternalService.RetryWaitTime,
+		RetryMaxWaitTime: config.ExternalService.RetryMaxWaitTime,
+	}
+	enrichers, err := enricher.NewRegistry().Build(config.ExternalService.Providers, opts)
+	if err != nil {
+		panic(err)
+	}
+	cache := enricher.NewCache(
+		store.EnrichmentCache(),
+		config.ExternalService.CacheSize,
+		config.ExternalService.CacheTTL,
+		logger,
+	)
+	for i, e := range enrichers {
+		enrichers[i] = cache.Wrap(e)
+	}
+	composite := enricher.NewComposite(logger, enrichers...)
+	scheme, ok := normalize.Schemes[strings.ToLower(config.Enrichment.Transliteration)]
+	if !ok {
+		panic(fmt.Errorf("unknown transliteration scheme %q", config.Enrichment.Transliteration))
+	}
+	enrichment := pipeline.New(store.Human(), composite, pipeline.Config{
+		Workers:       config.Enrichment.Workers,
+		QueueSize:     config.Enrichment.QueueSize,
+		MaxAttempts:   config.Enrichment.MaxAttempts,
+		RetryWait:     config.Enrichment.RetryWait,
+		RetryMaxWait:  config.Enrichment.RetryMaxWait,
+		Timeout:       config.ExternalService.EnrichTimeout,
+		SweepInterval: config.Enrichment.SweepInterval,
+
+		RefreshAge:      config.Enrichment.RefreshAge,
+		RefreshInterval: config.Enrichment.RefreshInterval,
+		RefreshBatch:    config.Enrichment.RefreshBatch,
+		Scheme:          scheme,
+	}, logger)
 	return &server{
-		router:      chi.NewRouter(),
-		config:      config,
-		logger:      logger,
-		store:       store,
-		agify:       agify.New(config.ExternalService.AgifyURL),
-		genderize:   genderize.New(config.ExternalService.GenderizeURL),
-		nationalize: nationalize.New(config.ExternalService.NationalizeURL),
+		router:   chi.NewRouter(),
+		config:   config,
+		logger:   logger,
+		store:    store,
+		cache:    cache,
+		pipeline: enrichment,
+		imports:  newImportJobs(config.Import.JobTTL),
+		jobsCtx:  context.Background(),
+	}
+}
+
+// start launches the background jobs; they stop when ctx is done
+func (s *server) start(ctx context.Context) {
+	s.jobsCtx = ctx
+	s.pipeline.Start(ctx)
+
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		s.backfillSearchKeys(ctx)
+	}()
+	if s.config.Retention.Deleted > 0 && s.config.Retention.PurgeInterval > 0 && s.config.Retention.PurgeBatch > 0 {
+		s.jobs.Add(1)
+		go func() {
+			defer s.jobs.Done()
+			s.purgeDeleted(ctx)
+		}()
+	}
+}
+
+// backfillSearchKeys fills the search keys of humans created before they were introduced
+func (s *server) backfillSearchKeys(ctx context.Context) {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := s.store.Human().BackfillSearchKeys(ctx, searchKeyBatch)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("search key backfill failed", zap.Error(err))
+			}
+			return
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+	}
+	if total > 0 {
+		s.logger.Info("search keys backfilled", zap.Int("humans", total))
 	}
 }
 
+// purgeDeleted periodically removes humans deleted longer than the retention period ago
+func (s *server) purgeDeleted(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Retention.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var total int64
+		for ctx.Err() == nil {
+			n, err := s.store.Human().PurgeDeletedHumans(ctx,
+				time.Now().Add(-s.config.Retention.Deleted), s.config.Retention.PurgeBatch)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Error("failed to purge deleted humans", zap.Error(err))
+				}
+				break
+			}
+			total += n
+			if n < int64(s.config.Retention.PurgeBatch) {
+				break
+			}
+		}
+		if total > 0 {
+			s.logger.Info("purged deleted humans", zap.Int64("humans", total))
+		}
+	}
+}
+
+// wait blocks until the background jobs have stopped
+func (s *server) wait() {
+	s.jobs.Wait()
+	s.pipeline.Wait()
+}
+
 func (s *server) configureRouter() {
+	s.router.Use(middleware.RequestID, requestIDHeader, audit)
 	s.router.Mount("/swagger", httpSwagger.WrapHandler)
 	s.router.Route("/humans", func(r chi.Router) {
 		r.Get("/", s.getHumans())
 		r.Post("/", s.addHuman())
-		r.Delete("/", s.deleteHuman())
-		r.Patch("/", s.updateHuman())
+		// Устаревшие маршруты: ID передаётся в теле запроса
+		r.With(deprecated).Delete("/", s.deleteHuman())
+		r.With(deprecated).Patch("/", s.updateHuman())
+		r.Post("/enrich", s.enrichHumans())
+		r.Post("/batch", s.addHumansBatch())
+		r.Post("/import", s.importHumans())
+		r.Get("/import/{jobID}", s.getImportJob())
+		r.Get("/export", s.exportHumans())
+		r.Get("/search", s.searchHumans())
+		r.Get("/duplicates", s.getDuplicates())
+		r.Post("/merge", s.mergeHumans())
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", s.getHuman())
+			r.Patch("/", s.patchHuman())
+			r.Put("/", s.replaceHuman())
+			r.Delete("/", s.deleteHumanByID())
+			r.Post("/enrich", s.enrichHuman())
+			r.Get("/history", s.getHumanHistory())
+			r.Post("/restore", s.restoreHuman())
+		})
+	})
+	s.router.Get("/v2/humans", s.getHumansV2())
+	s.router.Route("/admin", func(r chi.Router) {
+		r.Delete("/enrichment-cache", s.invalidateEnrichmentCache())
 	})
 }
 
+// deprecated marks a route as superseded by the /humans/{id} resource routes.
+// The ID of the human is in the request body, so no Link to the successor is sent;
+// the swagger descriptions name it instead.
+func deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// audit attaches the actor from the X-Actor header and the request ID
+// to the request context, so that the changes made by the request are attributed to them
+func audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := store.WithAudit(r.Context(), store.Audit{
+			Actor:     strings.TrimSpace(r.Header.Get("X-Actor")),
+			RequestID: middleware.GetReqID(r.Context()),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// humanID extracts the human ID from the URL path
+func humanID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		return 0, errInvalidHumanID
+	}
+	return id, nil
+}
+
+// decodeJSON checks the request media type and decodes the body into v
+func (s *server) decodeJSON(r *http.Request, v any) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return errUnsupportedMediaType
+	}
+	return s.decodeBody(r, v)
+}
+
+// respond writes data as a JSON response with the given status
+func (s *server) respond(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		s.logger.Error("error encoding response", zap.Error(err))
+	}
+}
+
 // addHuman adds a new human record
 // @Summary Create a human
-// @Description Create a new human with auto-filled age, gender, nationality
+// @Description Create a new human. Age, gender and nationality are filled in the background;
+// @Description poll the record until enrichment_status becomes enriched or failed
 // @Tags humans
 // @Accept application/json
 // @Produce application/json
 // @Param body body addHumanRequest true "Add Human payload"
-// @Success 201 {object} model.Human
-// @Failure 400 {object} string "name and surname required"
-// @Failure 500 {string} string "Internal Server Error"
-// @Router /humans [put]
+// @Success 202 {object} model.Human
+// @Header 202 {string} Location "URL of the created human"
+// @Header 202 {string} ETag "Version of the human"
+// @Failure 400 {object} problem
+// @Failure 415 {object} problem
+// @Failure 422 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans [post]
 func (s *server) addHuman() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
-			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
-			return
-		}
 		var req addHumanRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			s.logger.Info("error decoding request", zap.Error(err))
-			http.Error(w, ErrJsonDecodeError, http.StatusBadRequest)
+		if err := s.decodeJSON(r, &req); err != nil {
+			s.error(w, r, err)
 			return
 		}
-		if req.Name == "" || req.Surname == "" {
-			http.Error(w, ErrNameAndSurnameRequired, http.StatusBadRequest)
+		trimNames(&req.Name, &req.Surname, &req.Patronymic)
+		if err := validateRequest(&req); err != nil {
+			s.error(w, r, err)
 			return
 		}
 
+		// Возраст, пол и национальность заполняются фоновым обогащением
 		human := model.Human{
-			Name:       req.Name,
-			Surname:    req.Surname,
-			Patronymic: req.Patronymic,
+			Name:             req.Name,
+			Surname:          req.Surname,
+			Patronymic:       req.Patronymic,
+			Gender:           "unknown",
+			EnrichmentStatus: model.EnrichmentPending,
 		}
 
-		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
-		defer cancel()
-
-		g, ctx := errgroup.WithContext(ctx)
-
-		var (
-			age         = 0
-			gender      = "unknown"
-			nationality = ""
-		)
-
-		// Запрос к Agify
-		g.Go(func() error {
-			resp, err := s.agify.Get(req.Name)
-			if err != nil {
-				s.logger.Error("agify Get Error", zap.Error(err))
-				return err
-			}
-			select {
-			case <-ctx.Done():
-				s.logger.Warn("agify Get Timeout")
-				return ctx.Err()
-			default:
-				age = resp.Age
-				s.logger.Info("agify Get Success", zap.Any("resp", resp))
-				return nil
-			}
-		})
-
-		// Запрос к Genderize
-		g.Go(func() error {
-			resp, err := s.genderize.Get(req.Name)
-			if err != nil {
-				s.logger.Error("genderize Get Error", zap.Error(err))
-				return err
-			}
-			select {
-			case <-ctx.Done():
-				s.logger.Info("genderize Get Timeout")
-				return ctx.Err()
-			default:
-				if resp.Gender == "male" || resp.Gender == "female" {
-					gender = resp.Gender
-				}
-				s.logger.Info("genderize Get Success", zap.Any("resp", resp))
-				return nil
-			}
-		})
-
-		// Запрос к Nationalize
-		g.Go(func() error {
-			resp, err := s.nationalize.Get(req.Name)
-			if err != nil {
-				s.logger.Error("nationalize Get Error", zap.Error(err))
-				return err
-			}
-			select {
-			case <-ctx.Done():
-				s.logger.Info("nationalize Get Timeout")
-				return ctx.Err()
-			default:
-				if len(resp.Country) > 0 {
-					nationality = resp.Country[0].CountryId
-				}
-				s.logger.Info("nationalize Get Success", zap.Any("resp", resp))
-				return nil
-			}
-		})
-
-		// Ждём завершения всех горутин или таймаута
-		if err := g.Wait(); err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
-				s.logger.Warn("one or more services timed out")
-			} else {
-				s.logger.Error("Failed to send request", zap.Error(err))
-			}
-		}
-
-		human.Age = age
-		human.Gender = gender
-		human.Nationality = nationality
-
-		s.logger.Info("added Human", zap.Any("human", human))
-
 		if err := s.store.Human().AddHuman(r.Context(), &human); err != nil {
-			s.logger.Error("failed to save human", zap.Error(err))
-			http.Error(w, ErrJsonDecodeError, http.StatusInternalServerError)
+			s.error(w, r, err)
 			return
 		}
+		s.logger.Info("added Human", zap.Any("human", human))
+		s.pipeline.Enqueue(human.Id)
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		if err := json.NewEncoder(w).Encode(human); err != nil {
-			s.logger.Error("error encoding response", zap.Error(err))
-		}
+		w.Header().Set("Location", humanLocation(human.Id))
+		setHumanValidators(w, &human)
+		s.respond(w, http.StatusAccepted, human)
 	}
 }
 
 // getHumans retrieves filtered list of humans
 // @Summary Get humans
-// @Description Retrieve humans with optional filtering and pagination
+// @Description Retrieve humans with optional filtering, sorting and pagination.
+// @Description Offset mode (page, page_size) returns an array unless the X-Envelope: true header asks for a humansPage.
+// @Description Passing cursor, empty for the first page, switches to keyset pagination and always returns a humansPage;
+// @Description its next_cursor is passed as cursor to get the following page. Without sort cursor mode lists newest first.
 // @Tags humans
 // @Accept json
 // @Produce json
@@ -217,36 +334,102 @@ func (s *server) addHuman() http.HandlerFunc {
 // @Param patronymic query string false "Patronymic filter"
 // @Param gender query string false "Gender filter"
 // @Param nationality query string false "Nationality filter"
+// @Param nationality_any query string false "Comma-separated countries matched against every nationality candidate, e.g. RU,UA"
+// @Param nationality_min_probability query number false "Minimum candidate probability for nationality_any"
+// @Param enrichment_status query string false "Enrichment status filter" Enums(pending, enriched, failed)
 // @Param min_age query int false "Minimum age filter"
 // @Param max_age query int false "Maximum age filter"
+// @Param filter query string false "Filter expression combined with the other filters by AND" example(nationality in ('RU','KZ') and age >= 18)
+// @Param include_deleted query bool false "Also return deleted humans that are not purged yet, with deleted_at set"
+// @Param created_after query string false "Only humans created after this RFC 3339 time" example(2025-05-25T00:00:00Z)
+// @Param created_before query string false "Only humans created before this RFC 3339 time"
+// @Param updated_since query string false "Only humans changed at or after this RFC 3339 time" example(2025-05-25T00:00:00Z)
+// @Param updated_before query string false "Only humans last changed before this RFC 3339 time"
+// @Param sort query string false "Comma-separated sort columns, - for descending: id, name, surname, patronymic, age, gender, nationality, created_at, updated_at" example(age,-surname)
 // @Param page query int false "Page number"
 // @Param page_size query int false "Page size"
+// @Param cursor query string false "Keyset pagination cursor; empty for the first page"
+// @Param X-Envelope header bool false "Return a humansPage instead of an array"
 // @Success 200 {array} model.Human
-// @Failure 500 {string} string "Internal Server Error"
+// @Failure 400 {object} problem
+// @Failure 500 {object} problem
 // @Router /humans [get]
 func (s *server) getHumans() http.HandlerFunc {
+	return s.listHumans(false)
+}
+
+// getHumansV2 retrieves filtered page of humans in an envelope
+// @Summary Get humans page
+// @Description Same as GET /humans, but always returns a humansPage with the total number of matches.
+// @Description Above COUNT_EXACT_LIMIT matches the total is the planner estimate and total_estimated is set.
+// @Tags humans
+// @Accept json
+// @Produce json
+// @Param name query string false "Name filter"
+// @Param surname query string false "Surname filter"
+// @Param patronymic query string false "Patronymic filter"
+// @Param gender query string false "Gender filter"
+// @Param nationality query string false "Nationality filter"
+// @Param nationality_any query string false "Comma-separated countries matched against every nationality candidate, e.g. RU,UA"
+// @Param nationality_min_probability query number false "Minimum candidate probability for nationality_any"
+// @Param enrichment_status query string false "Enrichment status filter" Enums(pending, enriched, failed)
+// @Param min_age query int false "Minimum age filter"
+// @Param max_age query int false "Maximum age filter"
+// @Param filter query string false "Filter expression combined with the other filters by AND" example(nationality in ('RU','KZ') and age >= 18)
+// @Param include_deleted query bool false "Also return deleted humans that are not purged yet, with deleted_at set"
+// @Param created_after query string false "Only humans created after this RFC 3339 time" example(2025-05-25T00:00:00Z)
+// @Param created_before query string false "Only humans created before this RFC 3339 time"
+// @Param updated_since query string false "Only humans changed at or after this RFC 3339 time" example(2025-05-25T00:00:00Z)
+// @Param updated_before query string false "Only humans last changed before this RFC 3339 time"
+// @Param sort query string false "Comma-separated sort columns, - for descending: id, name, surname, patronymic, age, gender, nationality, created_at, updated_at" example(age,-surname)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param cursor query string false "Keyset pagination cursor; empty for the first page"
+// @Success 200 {object} humansPage
+// @Failure 400 {object} problem
+// @Failure 500 {object} problem
+// @Router /v2/humans [get]
+func (s *server) getHumansV2() http.HandlerFunc {
+	return s.listHumans(true)
+}
+
+func (s *server) listHumans(envelope bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		q := r.URL.Query()
-		f := &model.HumanFilter{
-			Name:        q.Get("name"),
-			Surname:     q.Get("surname"),
-			Patronymic:  q.Get("patronymic"),
-			Gender:      q.Get("gender"),
-			Nationality: q.Get("nationality"),
-		}
-
-		parseInt := func(key string, dest *int) {
-			if s := q.Get(key); s != "" {
-				if v, err := strconv.Atoi(s); err == nil {
-					*dest = v
+		f, err := s.humanFilter(q)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		parseQueryInt(q, "page", &f.Page)
+		parseQueryInt(q, "page_size", &f.PageSize)
+
+		if v := q.Get("sort"); v != "" {
+			sort, err := model.ParseSort(v)
+			if err != nil {
+				s.error(w, r, invalidQuery("sort", err.Error()))
+				return
+			}
+			f.Sort = model.WithTieBreaker(sort)
+		}
+
+		// Параметр cursor, даже пустой, включает keyset-пагинацию
+		cursorMode := q.Has("cursor")
+		if cursorMode {
+			if f.Sort == nil {
+				f.Sort = model.WithTieBreaker(model.DefaultSort)
+			}
+			if token := q.Get("cursor"); token != "" {
+				c, err := model.DecodeCursor(token, f.Sort)
+				if err != nil {
+					s.error(w, r, err)
+					return
 				}
+				f.After = c
 			}
+			f.Page = 1
 		}
-		parseInt("id", &f.ID)
-		parseInt("min_age", &f.MinAge)
-		parseInt("max_age", &f.MaxAge)
-		parseInt("page", &f.Page)
-		parseInt("page_size", &f.PageSize)
+		envelope = envelope || cursorMode || r.Header.Get("X-Envelope") == "true"
 
 		if f.Page < 1 {
 			f.Page = 1
@@ -258,44 +441,173 @@ func (s *server) getHumans() http.HandlerFunc {
 
 		humans, err := s.store.Human().GetHumans(r.Context(), f)
 		if err != nil {
-			s.logger.Error("failed to get humans", zap.Error(err))
-			http.Error(w, ErrInternalServer, http.StatusInternalServerError)
+			s.error(w, r, err)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(humans); err != nil {
-			s.logger.Error("error encoding response", zap.Error(err))
+		if !envelope {
+			s.respond(w, http.StatusOK, humans)
+			return
 		}
-		return
+
+		page := humansPage{Items: humans, PageSize: f.PageSize}
+		if page.Items == nil {
+			page.Items = []model.Human{}
+		}
+		if cursorMode {
+			if len(humans) == f.PageSize {
+				page.NextCursor = model.NewCursor(&humans[len(humans)-1], f.Sort).Encode()
+			}
+		} else {
+			page.Page = f.Page
+		}
+		page.Total, page.TotalEstimated, err = s.store.Human().CountHumans(r.Context(), f, s.config.Search.CountExactLimit)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		s.respond(w, http.StatusOK, page)
 	}
 }
 
-// deleteHuman deletes a human by ID
-// @Summary Delete human
-// @Description Delete a human record by ID
+// searchHumans finds humans by a misspelled name
+// @Summary Search humans
+// @Description Fuzzy search across name, surname and patronymic by trigram similarity.
+// @Description Cyrillic and Latin spellings match each other, e.g. Dmitriy finds Дмитрий.
+// @Description Hits below SEARCH_SIMILARITY_THRESHOLD are skipped; the best matches come first.
+// @Tags humans
+// @Produce json
+// @Param q query string true "Search text, e.g. Ivonov"
+// @Param limit query int false "Maximum number of hits (default 20, at most 100)"
+// @Success 200 {array} model.SearchHit
+// @Failure 400 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/search [get]
+func (s *server) searchHumans() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		text := strings.TrimSpace(q.Get("q"))
+		if text == "" {
+			s.error(w, r, invalidQuery("q", "q is required"))
+			return
+		}
+		limit := 20
+		parseQueryInt(q, "limit", &limit)
+		if limit <= 0 || limit > 100 {
+			limit = 20
+		}
+
+		hits, err := s.store.Human().SearchHumans(r.Context(), text, s.config.Search.SimilarityThreshold, limit)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		if hits == nil {
+			hits = []model.SearchHit{}
+		}
+		s.respond(w, http.StatusOK, hits)
+	}
+}
+
+// humanFilter reads the filter parameters shared by the list and export endpoints
+func (s *server) humanFilter(q url.Values) (*model.HumanFilter, error) {
+	f := &model.HumanFilter{
+		Name:        q.Get("name"),
+		Surname:     q.Get("surname"),
+		Patronymic:  q.Get("patronymic"),
+		Gender:      q.Get("gender"),
+		Nationality: q.Get("nationality"),
+
+		EnrichmentStatus: q.Get("enrichment_status"),
+	}
+	switch f.EnrichmentStatus {
+	case "", model.EnrichmentPending, model.EnrichmentEnriched, model.EnrichmentFailed:
+	default:
+		return nil, invalidQuery("enrichment_status", "enrichment_status must be pending, enriched or failed")
+	}
+	parseQueryInt(q, "id", &f.ID)
+	parseQueryInt(q, "min_age", &f.MinAge)
+	parseQueryInt(q, "max_age", &f.MaxAge)
+	f.IncludeDeleted, _ = strconv.ParseBool(q.Get("include_deleted"))
+	for key, dest := range map[string]*time.Time{
+		"created_after":  &f.CreatedAfter,
+		"created_before": &f.CreatedBefore,
+		"updated_since":  &f.UpdatedSince,
+		"updated_before": &f.UpdatedBefore,
+	} {
+		if v := q.Get(key); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, invalidQuery(key, key+" must be an RFC 3339 time such as 2025-05-25T00:00:00Z")
+			}
+			*dest = t
+		}
+	}
+
+	if v := q.Get("nationality_any"); v != "" {
+		for _, c := range strings.Split(v, ",") {
+			if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+				f.NationalityAny = append(f.NationalityAny, c)
+			}
+		}
+	}
+	f.NationalityMinProbability = s.config.Search.NationalityMinProbability
+	if v, err := strconv.ParseFloat(q.Get("nationality_min_probability"), 64); err == nil {
+		f.NationalityMinProbability = v
+	}
+
+	if v := q.Get("filter"); v != "" {
+		expr, err := filter.Parse(v, model.FilterFields)
+		if err != nil {
+			return nil, invalidFilter(err)
+		}
+		f.Expr = expr
+	}
+	return f, nil
+}
+
+// parseQueryInt stores an integer query parameter in dest, ignoring malformed values
+func parseQueryInt(q url.Values, key string, dest *int) {
+	if s := q.Get(key); s != "" {
+		if v, err := strconv.Atoi(s); err == nil {
+			*dest = v
+		}
+	}
+}
+
+// deleteHuman deletes a human by ID passed in the body
+// @Summary Delete human (deprecated)
+// @Description Delete a human record by ID. Deprecated: use DELETE /humans/{id}
 // @Tags humans
 // @Accept json
 // @Param id body apiserver.deleteHumanRequest true "Delete Human request"
+// @Param If-Match header string false "ETag of the human as last read; the change is rejected with 412 when the human has changed since"
 // @Success 200 {string} string "OK"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 415 {string} string "Unsupported Media Type"
-// @Failure 500 {string} string "Internal Server Error"
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 412 {object} problem
+// @Failure 415 {object} problem
+// @Failure 500 {object} problem
+// @Deprecated
 // @Router /humans [delete]
 func (s *server) deleteHuman() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
-			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		cond, err := ifMatch(r)
+		if err != nil {
+			s.error(w, r, err)
 			return
 		}
 		req := deleteHumanRequest{}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, ErrJsonDecodeError, http.StatusBadRequest)
+		if err := s.decodeJSON(r, &req); err != nil {
+			s.error(w, r, err)
+			return
+		}
+		version, err := cond.version(s.currentVersion(r, req.ID, false))
+		if err != nil {
+			s.error(w, r, err)
 			return
 		}
-		if err := s.store.Human().DeleteHuman(r.Context(), req.ID); err != nil {
-			s.logger.Error("failed to delete human", zap.Error(err))
-			http.Error(w, ErrInternalServer, http.StatusInternalServerError)
+		if err := s.store.Human().DeleteHuman(r.Context(), req.ID, version); err != nil {
+			s.error(w, r, cond.check(err))
 			return
 		}
 		s.logger.Info("deleted human", zap.Int("id", req.ID))
@@ -304,26 +616,37 @@ func (s *server) deleteHuman() http.HandlerFunc {
 	}
 }
 
-// updateHuman updates an existing human record
-// @Summary Update human
-// @Description Update human fields by ID
+// updateHuman updates an existing human record by ID passed in the body
+// @Summary Update human (deprecated)
+// @Description Update human fields by ID. Deprecated: use PATCH /humans/{id}
 // @Tags humans
 // @Accept json
 // @Param human body apiserver.updateHumanRequest true "Update Human request"
+// @Param If-Match header string false "ETag of the human as last read; the change is rejected with 412 when the human has changed since"
 // @Success 200 {string} string "OK"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 415 {string} string "Unsupported Media Type"
-// @Failure 500 {string} string "Internal Server Error"
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 412 {object} problem
+// @Failure 415 {object} problem
+// @Failure 422 {object} problem
+// @Failure 500 {object} problem
+// @Deprecated
 // @Router /humans [patch]
 func (s *server) updateHuman() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
-			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		cond, err := ifMatch(r)
+		if err != nil {
+			s.error(w, r, err)
 			return
 		}
 		req := updateHumanRequest{}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, ErrJsonDecodeError, http.StatusBadRequest)
+		if err := s.decodeJSON(r, &req); err != nil {
+			s.error(w, r, err)
+			return
+		}
+		trimNames(&req.Name, &req.Surname, &req.Patronymic)
+		if err := validateRequest(&req); err != nil {
+			s.error(w, r, err)
 			return
 		}
 
@@ -337,9 +660,13 @@ func (s *server) updateHuman() http.HandlerFunc {
 			Nationality: req.Nationality,
 		}
 
-		if err := s.store.Human().UpdateHuman(r.Context(), &human); err != nil {
-			s.logger.Error("failed to update human", zap.Error(err))
-			http.Error(w, ErrInternalServer, http.StatusInternalServerError)
+		version, err := cond.version(s.currentVersion(r, human.Id, false))
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		if err := s.store.Human().UpdateHuman(r.Context(), human.Id, version, nonEmptyPatch(&human)); err != nil {
+			s.error(w, r, cond.check(err))
 			return
 		}
 		s.logger.Info("updated human", zap.Any("human", human))
@@ -348,3 +675,428 @@ func (s *server) updateHuman() http.HandlerFunc {
 
 	}
 }
+
+// getHuman retrieves a single human by ID
+// @Summary Get human
+// @Description Retrieve a human record by ID. Supports conditional requests with If-None-Match and If-Modified-Since.
+// @Tags humans
+// @Produce json
+// @Param id path int true "Human ID"
+// @Param If-None-Match header string false "ETag of the cached human"
+// @Param If-Modified-Since header string false "Time the cached human was last modified, in HTTP date format"
+// @Success 200 {object} model.Human
+// @Header 200 {string} ETag "Version of the human"
+// @Header 200 {string} Last-Modified "Time of the last change of the human"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/{id} [get]
+func (s *server) getHuman() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := humanID(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		human, err := s.store.Human().GetHuman(r.Context(), id)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		setHumanValidators(w, human)
+		if notModified(r, human) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.respond(w, http.StatusOK, human)
+	}
+}
+
+// patchHuman partially updates a human by ID
+// @Summary Patch human
+// @Description Update human fields by ID. With application/json only non-empty fields are changed.
+// @Description With application/merge-patch+json (RFC 7396) null clears a field; with application/json-patch+json (RFC 6902)
+// @Description the body is an array of operations on paths like /age, and remove clears a field. Name and surname cannot be cleared.
+// @Tags humans
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "Human ID"
+// @Param human body apiserver.patchHumanRequest true "Patch Human request, a merge patch or an array of patch.Operation"
+// @Param If-Match header string false "ETag of the human as last read; the change is rejected with 412 when the human has changed since"
+// @Success 200 {object} model.Human
+// @Header 200 {string} ETag "Version of the human"
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 409 {object} problem
+// @Failure 412 {object} problem
+// @Failure 415 {object} problem
+// @Failure 422 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/{id} [patch]
+func (s *server) patchHuman() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := humanID(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		cond, err := ifMatch(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		var (
+			changes *model.HumanPatch
+			version int
+		)
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "application/json":
+			req := patchHumanRequest{}
+			if err := s.decodeBody(r, &req); err != nil {
+				s.error(w, r, err)
+				return
+			}
+			trimNames(&req.Name, &req.Surname, &req.Patronymic)
+			if err := validateRequest(&req); err != nil {
+				s.error(w, r, err)
+				return
+			}
+			changes = nonEmptyPatch(&model.Human{
+				Name:        req.Name,
+				Surname:     req.Surname,
+				Patronymic:  req.Patronymic,
+				Age:         req.Age,
+				Gender:      req.Gender,
+				Nationality: req.Nationality,
+			})
+			if version, err = cond.version(s.currentVersion(r, id, false)); err != nil {
+				s.error(w, r, err)
+				return
+			}
+		case mergePatchContentType, jsonPatchContentType:
+			// Патч применяется к прочитанной версии, поэтому изменение записи после чтения
+			// отклоняется так же, как несовпадение If-Match
+			current, err := s.store.Human().GetHuman(r.Context(), id)
+			if err != nil {
+				s.error(w, r, cond.check(err))
+				return
+			}
+			expected, err := cond.version(func() (int, error) { return current.Version, nil })
+			if err != nil {
+				s.error(w, r, err)
+				return
+			}
+			if expected > 0 && expected != current.Version {
+				s.error(w, r, errPreconditionFailed)
+				return
+			}
+			version = current.Version
+			if changes, err = s.patchDocument(r, current, mediaType); err != nil {
+				s.error(w, r, err)
+				return
+			}
+			if changes == nil {
+				s.respondHuman(w, r, id)
+				return
+			}
+		default:
+			s.error(w, r, errUnsupportedPatchType)
+			return
+		}
+
+		if err := s.store.Human().UpdateHuman(r.Context(), id, version, changes); err != nil {
+			s.error(w, r, cond.check(err))
+			return
+		}
+		s.logger.Info("updated human", zap.Int("id", id))
+		s.respondHuman(w, r, id)
+	}
+}
+
+// replaceHuman replaces all fields of a human by ID
+// @Summary Replace human
+// @Description Replace all human fields by ID
+// @Tags humans
+// @Accept json
+// @Produce json
+// @Param id path int true "Human ID"
+// @Param human body apiserver.replaceHumanRequest true "Replace Human request"
+// @Param If-Match header string false "ETag of the human as last read; the change is rejected with 412 when the human has changed since"
+// @Success 200 {object} model.Human
+// @Header 200 {string} ETag "Version of the human"
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 412 {object} problem
+// @Failure 415 {object} problem
+// @Failure 422 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/{id} [put]
+func (s *server) replaceHuman() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := humanID(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		cond, err := ifMatch(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		req := replaceHumanRequest{}
+		if err := s.decodeJSON(r, &req); err != nil {
+			s.error(w, r, err)
+			return
+		}
+		trimNames(&req.Name, &req.Surname, &req.Patronymic)
+		if err := validateRequest(&req); err != nil {
+			s.error(w, r, err)
+			return
+		}
+		if req.Gender == "" {
+			req.Gender = "unknown"
+		}
+		version, err := cond.version(s.currentVersion(r, id, false))
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		human := model.Human{
+			Id:          id,
+			Name:        req.Name,
+			Surname:     req.Surname,
+			Patronymic:  req.Patronymic,
+			Age:         req.Age,
+			Gender:      req.Gender,
+			Nationality: req.Nationality,
+			Version:     version,
+		}
+		if err := s.store.Human().ReplaceHuman(r.Context(), &human); err != nil {
+			s.error(w, r, cond.check(err))
+			return
+		}
+		s.logger.Info("replaced human", zap.Any("human", human))
+		s.respondHuman(w, r, id)
+	}
+}
+
+// deleteHumanByID deletes a human by ID from the URL path
+// @Summary Delete human by ID
+// @Description Mark a human as deleted. The human is hidden from every endpoint except GET /humans with include_deleted
+// @Description and the history, can be restored with POST /humans/{id}/restore and is removed for good after DELETED_RETENTION
+// @Tags humans
+// @Param id path int true "Human ID"
+// @Param If-Match header string false "ETag of the human as last read; the change is rejected with 412 when the human has changed since"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 412 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/{id} [delete]
+func (s *server) deleteHumanByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := humanID(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		cond, err := ifMatch(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		version, err := cond.version(s.currentVersion(r, id, false))
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		if err := s.store.Human().DeleteHuman(r.Context(), id, version); err != nil {
+			s.error(w, r, cond.check(err))
+			return
+		}
+		s.logger.Info("deleted human", zap.Int("id", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// restoreHuman restores a deleted human
+// @Summary Restore human
+// @Description Clear the deletion mark of a human that was not purged yet. Restoring a human that is not deleted changes nothing.
+// @Tags humans
+// @Produce json
+// @Param id path int true "Human ID"
+// @Param If-Match header string false "ETag of the deleted human as last read; the change is rejected with 412 when the human has changed since"
+// @Success 200 {object} model.Human
+// @Header 200 {string} ETag "Version of the human"
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 412 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/{id}/restore [post]
+func (s *server) restoreHuman() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := humanID(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		cond, err := ifMatch(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		version, err := cond.version(s.currentVersion(r, id, true))
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		if err := s.store.Human().RestoreHuman(r.Context(), id, version); err != nil {
+			s.error(w, r, cond.check(err))
+			return
+		}
+		s.logger.Info("restored human", zap.Int("id", id))
+		s.respondHuman(w, r, id)
+	}
+}
+
+// enrichHuman re-runs enrichment of a single human
+// @Summary Re-enrich human
+// @Description Move a human back to pending and enrich it again in the background
+// @Tags humans
+// @Produce json
+// @Param id path int true "Human ID"
+// @Success 202 {object} model.Human
+// @Failure 400 {object} problem
+// @Failure 404 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/{id}/enrich [post]
+func (s *server) enrichHuman() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := humanID(r)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		if err := s.store.Human().ResetEnrichment(r.Context(), id); err != nil {
+			s.error(w, r, err)
+			return
+		}
+		s.pipeline.Enqueue(id)
+		s.logger.Info("human queued for enrichment", zap.Int("id", id))
+
+		human, err := s.store.Human().GetHuman(r.Context(), id)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		s.respond(w, http.StatusAccepted, human)
+	}
+}
+
+// enrichHumans re-runs enrichment of humans by status
+// @Summary Re-enrich humans
+// @Description Move humans with the given status back to pending and enrich them again in the background.
+// @Description One request resets at most ENRICH_REFRESH_BATCH humans; while more is set, repeat it to reset the rest.
+// @Tags humans
+// @Produce json
+// @Param status query string false "Enrichment status to re-process" Enums(failed, enriched) default(failed)
+// @Param older_than query string false "Only humans enriched longer ago than this duration, e.g. 720h"
+// @Success 202 {object} enrichResponse
+// @Failure 400 {object} problem
+// @Failure 500 {object} problem
+// @Router /humans/enrich [post]
+func (s *server) enrichHumans() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		status := q.Get("status")
+		switch status {
+		case "":
+			status = model.EnrichmentFailed
+		case model.EnrichmentFailed, model.EnrichmentEnriched:
+		default:
+			s.error(w, r, invalidQuery("status", "status must be failed or enriched"))
+			return
+		}
+
+		var enrichedBefore time.Time
+		if v := q.Get("older_than"); v != "" {
+			age, err := time.ParseDuration(v)
+			if err != nil || age <= 0 {
+				s.error(w, r, invalidQuery("older_than", "older_than must be a positive duration"))
+				return
+			}
+			enrichedBefore = time.Now().Add(-age)
+		}
+
+		// Записи сбрасываются порциями, как при плановом обновлении: каждая порция — одна транзакция
+		// с записью истории на каждую запись
+		batch := s.config.Enrichment.RefreshBatch
+		if batch <= 0 {
+			batch = 100
+		}
+		ids, more, err := s.store.Human().ResetEnrichments(r.Context(), status, enrichedBefore, batch)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		s.pipeline.Requeue(ids)
+		s.logger.Info("humans queued for enrichment", zap.String("status", status), zap.Int("count", len(ids)), zap.Bool("more", more))
+		s.respond(w, http.StatusAccepted, enrichResponse{Queued: len(ids), More: more})
+	}
+}
+
+// currentVersion returns a reader of the version of a human, deleted ones included on request
+func (s *server) currentVersion(r *http.Request, id int, includeDeleted bool) func() (int, error) {
+	return func() (int, error) {
+		humans, err := s.store.Human().GetHumans(r.Context(), &model.HumanFilter{ID: id, IncludeDeleted: includeDeleted, PageSize: 1})
+		if err != nil {
+			return 0, err
+		}
+		if len(humans) == 0 {
+			return 0, store.ErrHumanNotFound
+		}
+		return humans[0].Version, nil
+	}
+}
+
+// humanLocation is the URL of the human resource
+func humanLocation(id int) string {
+	return "/humans/" + strconv.Itoa(id)
+}
+
+// respondHuman loads a human by ID and writes it to the response with its ETag and Last-Modified
+func (s *server) respondHuman(w http.ResponseWriter, r *http.Request, id int) {
+	human, err := s.store.Human().GetHuman(r.Context(), id)
+	if err != nil {
+		s.error(w, r, err)
+		return
+	}
+	setHumanValidators(w, human)
+	s.respond(w, http.StatusOK, human)
+}
+
+// invalidateEnrichmentCache drops cached enrichment results
+// @Summary Invalidate enrichment cache
+// @Description Remove cached agify/genderize/nationalize responses for a name, or all of them when name is omitted
+// @Tags admin
+// @Produce json
+// @Param name query string false "Name to invalidate"
+// @Success 200 {object} invalidateCacheResponse
+// @Failure 500 {object} problem
+// @Router /admin/enrichment-cache [delete]
+func (s *server) invalidateEnrichmentCache() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n, err := s.cache.Invalidate(r.Context(), r.URL.Query().Get("name"))
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		s.respond(w, http.StatusOK, invalidateCacheResponse{Deleted: n})
+	}
+}
@@ -1,26 +1,28 @@
 package agify
 
 import (
+	"context"
+	"effectiveMobile/internal/app/client"
 	"fmt"
 	"github.com/go-resty/resty/v2"
+	"net/url"
 )
 
 type Agify struct {
 	client *resty.Client
 }
 
-func New(url string) *Agify {
+func New(url string, opts client.Options) *Agify {
 	return &Agify{
-		client: resty.New().
-			SetBaseURL(url).
-			SetHeader("Accept", "application/json"),
+		client: client.NewResty(url, opts),
 	}
 }
 
-func (c *Agify) Get(name string) (Response, error) {
+func (c *Agify) Get(ctx context.Context, name string) (Response, error) {
 	var result Response
 
 	resp, err := c.client.R().
+		SetContext(ctx).
 		SetQueryParam("name", name).
 		SetResult(&result).
 		Get("/")
@@ -38,3 +40,34 @@ func (c *Agify) Get(name string) (Response, error) {
 
 	return result, nil
 }
+
+// GetBatch queries several names at once; results follow the order of names
+func (c *Agify) GetBatch(ctx context.Context, names []string) ([]Response, error) {
+	results := make([]Response, 0, len(names))
+	for start := 0; start < len(names); start += client.MaxBatchSize {
+		end := min(start+client.MaxBatchSize, len(names))
+
+		var chunk []Response
+		resp, err := c.client.R().
+			SetContext(ctx).
+			SetQueryParamsFromValues(url.Values{"name[]": names[start:end]}).
+			SetResult(&chunk).
+			Get("/")
+
+		if err != nil {
+			return nil, fmt.Errorf("agify GetBatch error: %w", err)
+		}
+		if resp.IsError() {
+			return nil, fmt.Errorf(
+				"agify GetBatch unexpected status %d: %s",
+				resp.StatusCode(), resp.String(),
+			)
+		}
+		if len(chunk) != end-start {
+			return nil, fmt.Errorf("agify GetBatch returned %d results for %d names", len(chunk), end-start)
+		}
+		results = append(results, chunk...)
+	}
+
+	return results, nil
+}
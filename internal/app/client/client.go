@@ -0,0 +1,85 @@
+// Package client contains the transport shared by the external enrichment clients.
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// MaxBatchSize is the largest number of names the services accept in one request
+const MaxBatchSize = 10
+
+// Options configures per-call timeouts and retries of an external service client
+type Options struct {
+	// Timeout ограничивает одну попытку запроса
+	Timeout time.Duration
+	// RetryCount — количество повторов после первой попытки
+	RetryCount       int
+	RetryWaitTime    time.Duration
+	RetryMaxWaitTime time.Duration
+}
+
+// NewResty creates a resty client for the service at url.
+// Transient failures (network errors, 5xx and 429) are retried with jittered
+// exponential backoff; Retry-After is honoured when the service sends it.
+func NewResty(url string, opts Options) *resty.Client {
+	return resty.New().
+		SetBaseURL(url).
+		SetHeader("Accept", "application/json").
+		SetTimeout(opts.Timeout).
+		SetRetryCount(opts.RetryCount).
+		SetRetryWaitTime(opts.RetryWaitTime).
+		SetRetryMaxWaitTime(opts.RetryMaxWaitTime).
+		SetRetryAfter(retryAfter).
+		AddRetryCondition(isTransient)
+}
+
+// isTransient reports whether a failed attempt is worth repeating
+func isTransient(resp *resty.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) ||
+			errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, io.EOF)
+	}
+	if resp == nil {
+		return false
+	}
+	code := resp.StatusCode()
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// retryAfter parses the Retry-After header of 429 and 503 responses.
+// Zero means the default backoff is used.
+func retryAfter(c *resty.Client, resp *resty.Response) (time.Duration, error) {
+	code := resp.StatusCode()
+	if code != http.StatusTooManyRequests && code != http.StatusServiceUnavailable {
+		return 0, nil
+	}
+	header := resp.Header().Get("Retry-After")
+	if header == "" {
+		return 0, nil
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(header); err == nil {
+		wait = time.Until(at)
+	} else {
+		return 0, nil
+	}
+
+	if c.RetryMaxWaitTime > 0 && wait > c.RetryMaxWaitTime {
+		return 0, fmt.Errorf("retry after %s exceeds max wait time %s", wait, c.RetryMaxWaitTime)
+	}
+	return wait, nil
+}
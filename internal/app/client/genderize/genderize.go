@@ -1,8 +1,11 @@
 package genderize
 
 import (
+	"context"
+	"effectiveMobile/internal/app/client"
 	"fmt"
 	"github.com/go-resty/resty/v2"
+	"net/url"
 )
 
 type Genderize struct {
@@ -10,18 +13,17 @@ type Genderize struct {
 }
 
 // New создаёт новый Genderize-клиент
-func New(url string) *Genderize {
+func New(url string, opts client.Options) *Genderize {
 	return &Genderize{
-		client: resty.New().
-			SetBaseURL(url).
-			SetHeader("Accept", "application/json"),
+		client: client.NewResty(url, opts),
 	}
 }
 
-func (c *Genderize) Get(name string) (Response, error) {
+func (c *Genderize) Get(ctx context.Context, name string) (Response, error) {
 	var result Response
 
 	resp, err := c.client.R().
+		SetContext(ctx).
 		SetQueryParam("name", name).
 		SetResult(&result).
 		Get("/")
@@ -38,3 +40,34 @@ func (c *Genderize) Get(name string) (Response, error) {
 
 	return result, nil
 }
+
+// GetBatch queries several names at once; results follow the order of names
+func (c *Genderize) GetBatch(ctx context.Context, names []string) ([]Response, error) {
+	results := make([]Response, 0, len(names))
+	for start := 0; start < len(names); start += client.MaxBatchSize {
+		end := min(start+client.MaxBatchSize, len(names))
+
+		var chunk []Response
+		resp, err := c.client.R().
+			SetContext(ctx).
+			SetQueryParamsFromValues(url.Values{"name[]": names[start:end]}).
+			SetResult(&chunk).
+			Get("/")
+
+		if err != nil {
+			return nil, fmt.Errorf("genderize GetBatch error: %w", err)
+		}
+		if resp.IsError() {
+			return nil, fmt.Errorf(
+				"genderize GetBatch unexpected status %d: %s",
+				resp.StatusCode(), resp.String(),
+			)
+		}
+		if len(chunk) != end-start {
+			return nil, fmt.Errorf("genderize GetBatch returned %d results for %d names", len(chunk), end-start)
+		}
+		results = append(results, chunk...)
+	}
+
+	return results, nil
+}
@@ -1,7 +1,10 @@
 package nationalize
 
 import (
+	"context"
+	"effectiveMobile/internal/app/client"
 	"fmt"
+	"net/url"
 
 	"github.com/go-resty/resty/v2"
 )
@@ -11,18 +14,17 @@ type Nationalize struct {
 }
 
 // New создаёт новый Nationalize-клиент
-func New(url string) *Nationalize {
+func New(url string, opts client.Options) *Nationalize {
 	return &Nationalize{
-		client: resty.New().
-			SetBaseURL(url).
-			SetHeader("Accept", "application/json"),
+		client: client.NewResty(url, opts),
 	}
 }
 
-func (c *Nationalize) Get(name string) (Response, error) {
+func (c *Nationalize) Get(ctx context.Context, name string) (Response, error) {
 	var result Response
 
 	resp, err := c.client.R().
+		SetContext(ctx).
 		SetQueryParam("name", name).
 		SetResult(&result).
 		Get("/")
@@ -39,3 +41,34 @@ func (c *Nationalize) Get(name string) (Response, error) {
 
 	return result, nil
 }
+
+// GetBatch queries several names at once; results follow the order of names
+func (c *Nationalize) GetBatch(ctx context.Context, names []string) ([]Response, error) {
+	results := make([]Response, 0, len(names))
+	for start := 0; start < len(names); start += client.MaxBatchSize {
+		end := min(start+client.MaxBatchSize, len(names))
+
+		var chunk []Response
+		resp, err := c.client.R().
+			SetContext(ctx).
+			SetQueryParamsFromValues(url.Values{"name[]": names[start:end]}).
+			SetResult(&chunk).
+			Get("/")
+
+		if err != nil {
+			return nil, fmt.Errorf("nationalize GetBatch error: %w", err)
+		}
+		if resp.IsError() {
+			return nil, fmt.Errorf(
+				"nationalize GetBatch unexpected status %d: %s",
+				resp.StatusCode(), resp.String(),
+			)
+		}
+		if len(chunk) != end-start {
+			return nil, fmt.Errorf("nationalize GetBatch returned %d results for %d names", len(chunk), end-start)
+		}
+		results = append(results, chunk...)
+	}
+
+	return results, nil
+}
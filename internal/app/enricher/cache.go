@@ -0,0 +1,154 @@
+package enricher
+
+import (
+	"context"
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/normalize"
+	"effectiveMobile/internal/store"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Cache keeps provider responses by normalized name in memory and in Postgres
+type Cache struct {
+	lru    *lru
+	ttl    time.Duration
+	repo   store.EnrichmentCacheRepository
+	logger *zap.Logger
+}
+
+// NewCache creates a cache holding up to size entries in memory.
+// Entries older than ttl are treated as missing.
+func NewCache(repo store.EnrichmentCacheRepository, size int, ttl time.Duration, logger *zap.Logger) *Cache {
+	return &Cache{
+		lru:    newLRU(size, ttl),
+		ttl:    ttl,
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// NormalizeName returns the cache key for a name
+func NormalizeName(name string) string {
+	return normalize.Fold(name)
+}
+
+// Wrap returns an enricher that consults the cache before calling e
+func (c *Cache) Wrap(e Enricher) Enricher {
+	return &cached{next: e, cache: c}
+}
+
+// Invalidate removes cached results for name, or all of them when name is empty
+func (c *Cache) Invalidate(ctx context.Context, name string) (int64, error) {
+	name = NormalizeName(name)
+	c.lru.remove(name)
+	n, err := c.repo.DeleteEntries(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	c.logger.Info("enrichment cache invalidated", zap.String("name", name), zap.Int64("entries", n))
+	return n, nil
+}
+
+func (c *Cache) get(ctx context.Context, key cacheKey) (Result, bool) {
+	if res, ok := c.lru.get(key); ok {
+		c.logger.Info("enrichment cache hit",
+			zap.String("provider", key.provider), zap.String("name", key.name), zap.String("source", "memory"))
+		return res, true
+	}
+
+	entry, err := c.repo.GetEntry(ctx, key.provider, key.name)
+	if err != nil {
+		if !errors.Is(err, store.ErrCacheMiss) {
+			c.logger.Warn("enrichment cache read failed", zap.Error(err))
+		}
+		c.logger.Info("enrichment cache miss", zap.String("provider", key.provider), zap.String("name", key.name))
+		return Result{}, false
+	}
+	if time.Since(entry.FetchedAt) > c.ttl {
+		c.logger.Info("enrichment cache miss",
+			zap.String("provider", key.provider), zap.String("name", key.name), zap.String("reason", "expired"))
+		return Result{}, false
+	}
+	var res Result
+	if err := json.Unmarshal(entry.Result, &res); err != nil {
+		c.logger.Warn("enrichment cache entry is corrupted", zap.Error(err))
+		return Result{}, false
+	}
+	c.lru.add(key, res, entry.FetchedAt)
+	c.logger.Info("enrichment cache hit",
+		zap.String("provider", key.provider), zap.String("name", key.name), zap.String("source", "postgres"))
+	return res, true
+}
+
+func (c *Cache) put(ctx context.Context, key cacheKey, res Result) {
+	now := time.Now()
+	c.lru.add(key, res, now)
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		c.logger.Warn("enrichment cache write failed", zap.Error(err))
+		return
+	}
+	entry := &model.EnrichmentCacheEntry{
+		Provider:  key.provider,
+		Name:      key.name,
+		Result:    data,
+		FetchedAt: now,
+	}
+	if err := c.repo.PutEntry(ctx, entry); err != nil {
+		c.logger.Warn("enrichment cache write failed", zap.Error(err))
+	}
+}
+
+// cached is an enricher decorated with Cache. Failed lookups are not cached.
+type cached struct {
+	next  Enricher
+	cache *Cache
+}
+
+func (c *cached) Name() string {
+	return c.next.Name()
+}
+
+func (c *cached) Enrich(ctx context.Context, name string) (Result, error) {
+	key := cacheKey{provider: c.next.Name(), name: NormalizeName(name)}
+	if res, ok := c.cache.get(ctx, key); ok {
+		return res, nil
+	}
+	res, err := c.next.Enrich(ctx, name)
+	if err != nil {
+		return Result{}, err
+	}
+	c.cache.put(ctx, key, res)
+	return res, nil
+}
+
+func (c *cached) EnrichBatch(ctx context.Context, names []string) (map[string]Result, error) {
+	results := make(map[string]Result, len(names))
+	var misses []string
+	for _, name := range names {
+		key := cacheKey{provider: c.next.Name(), name: NormalizeName(name)}
+		if res, ok := c.cache.get(ctx, key); ok {
+			results[name] = res
+			continue
+		}
+		misses = append(misses, name)
+	}
+	if len(misses) == 0 {
+		return results, nil
+	}
+
+	fetched, err := EnrichBatch(ctx, c.next, misses)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range misses {
+		res := fetched[name]
+		c.cache.put(ctx, cacheKey{provider: c.next.Name(), name: NormalizeName(name)}, res)
+		results[name] = res
+	}
+	return results, nil
+}
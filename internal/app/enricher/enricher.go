@@ -0,0 +1,165 @@
+// Package enricher infers demographic attributes of a person by name.
+package enricher
+
+import (
+	"context"
+	"effectiveMobile/internal/model"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sync"
+)
+
+// Result holds the attributes inferred for a name.
+// Zero values mean that the attribute could not be inferred.
+type Result struct {
+	Age         int    `json:"age,omitempty"`
+	Gender      string `json:"gender,omitempty"`
+	Nationality string `json:"nationality,omitempty"`
+	// Nationalities holds every candidate country ordered by probability
+	Nationalities []model.Nationality `json:"nationalities,omitempty"`
+
+	// Sources holds the provenance of every inferred attribute
+	Sources []model.Enrichment `json:"sources,omitempty"`
+}
+
+// Enricher infers attributes of a person from their name
+type Enricher interface {
+	// Name returns the provider name used in configuration and logs
+	Name() string
+	Enrich(ctx context.Context, name string) (Result, error)
+}
+
+// BatchEnricher is implemented by enrichers able to process several names
+// in one request. Results are keyed by the names passed in.
+type BatchEnricher interface {
+	EnrichBatch(ctx context.Context, names []string) (map[string]Result, error)
+}
+
+// EnrichBatch enriches names with e, one by one when e does not support batches
+func EnrichBatch(ctx context.Context, e Enricher, names []string) (map[string]Result, error) {
+	if b, ok := e.(BatchEnricher); ok {
+		return b.EnrichBatch(ctx, names)
+	}
+	results := make(map[string]Result, len(names))
+	for _, name := range names {
+		res, err := e.Enrich(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		results[name] = res
+	}
+	return results, nil
+}
+
+// merge fills the empty fields of r from other together with their sources
+func (r *Result) merge(other Result) {
+	taken := make(map[string]bool)
+	if r.Age == 0 && other.Age != 0 {
+		r.Age = other.Age
+		taken[model.AttributeAge] = true
+	}
+	if r.Gender == "" && other.Gender != "" {
+		r.Gender = other.Gender
+		taken[model.AttributeGender] = true
+	}
+	if r.Nationality == "" && other.Nationality != "" {
+		r.Nationality = other.Nationality
+		r.Nationalities = other.Nationalities
+		taken[model.AttributeNationality] = true
+	}
+	for _, src := range other.Sources {
+		if taken[src.Attribute] {
+			r.Sources = append(r.Sources, src)
+		}
+	}
+}
+
+// Composite queries several enrichers concurrently and merges their results.
+// Earlier enrichers take precedence when more than one infers the same attribute.
+type Composite struct {
+	enrichers []Enricher
+	logger    *zap.Logger
+}
+
+func NewComposite(logger *zap.Logger, enrichers ...Enricher) *Composite {
+	return &Composite{
+		enrichers: enrichers,
+		logger:    logger,
+	}
+}
+
+func (c *Composite) Name() string {
+	return "composite"
+}
+
+// Enrich returns whatever the enrichers managed to infer together with the
+// joined errors of the ones that failed. A failing enricher does not cancel
+// the others.
+func (c *Composite) Enrich(ctx context.Context, name string) (Result, error) {
+	var (
+		wg      sync.WaitGroup
+		results = make([]Result, len(c.enrichers))
+		errs    = make([]error, len(c.enrichers))
+	)
+	for i, e := range c.enrichers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := e.Enrich(ctx, name)
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					c.logger.Warn("enrich timeout", zap.String("provider", e.Name()))
+				} else {
+					c.logger.Error("enrich error", zap.String("provider", e.Name()), zap.Error(err))
+				}
+				errs[i] = fmt.Errorf("%s: %w", e.Name(), err)
+				return
+			}
+			c.logger.Info("enrich success", zap.String("provider", e.Name()), zap.Any("result", res))
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+
+	var result Result
+	for _, res := range results {
+		result.merge(res)
+	}
+	return result, errors.Join(errs...)
+}
+
+// EnrichBatch enriches names with every enricher concurrently. As with Enrich,
+// results of failed enrichers are missing and their errors are joined.
+func (c *Composite) EnrichBatch(ctx context.Context, names []string) (map[string]Result, error) {
+	var (
+		wg      sync.WaitGroup
+		results = make([]map[string]Result, len(c.enrichers))
+		errs    = make([]error, len(c.enrichers))
+	)
+	for i, e := range c.enrichers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := EnrichBatch(ctx, e, names)
+			if err != nil {
+				c.logger.Error("enrich batch error", zap.String("provider", e.Name()), zap.Int("names", len(names)), zap.Error(err))
+				errs[i] = fmt.Errorf("%s: %w", e.Name(), err)
+				return
+			}
+			c.logger.Info("enrich batch success", zap.String("provider", e.Name()), zap.Int("names", len(names)))
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+
+	merged := make(map[string]Result, len(names))
+	for _, name := range names {
+		var result Result
+		for _, res := range results {
+			result.merge(res[name])
+		}
+		merged[name] = result
+	}
+	return merged, errors.Join(errs...)
+}
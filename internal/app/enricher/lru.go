@@ -0,0 +1,91 @@
+package enricher
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type cacheKey struct {
+	provider string
+	name     string
+}
+
+type lruEntry struct {
+	key       cacheKey
+	result    Result
+	expiresAt time.Time
+}
+
+// lru is a fixed-size in-process cache with per-entry expiration
+type lru struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[cacheKey]*list.Element
+	order *list.List
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[cacheKey]*list.Element),
+		order: list.New(),
+	}
+}
+
+func (c *lru) get(key cacheKey) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return Result{}, false
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return Result{}, false
+	}
+	c.order.MoveToFront(el)
+	return entry.result, true
+}
+
+// add stores a result fetched at fetchedAt; it expires ttl after fetching
+func (c *lru) add(key cacheKey, result Result, fetchedAt time.Time) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := fetchedAt.Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.result = result
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, result: result, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// remove drops entries of all providers for name, or every entry when name is empty
+func (c *lru) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.items {
+		if name == "" || key.name == name {
+			c.order.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
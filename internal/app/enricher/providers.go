@@ -0,0 +1,171 @@
+package enricher
+
+import (
+	"context"
+	"effectiveMobile/internal/app/client"
+	"effectiveMobile/internal/app/client/agify"
+	"effectiveMobile/internal/app/client/genderize"
+	"effectiveMobile/internal/app/client/nationalize"
+	"effectiveMobile/internal/model"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Agify infers age using agify.io
+type Agify struct {
+	client *agify.Agify
+}
+
+func NewAgify(url string, opts client.Options) Enricher {
+	return &Agify{client: agify.New(url, opts)}
+}
+
+func (a *Agify) Name() string {
+	return "agify"
+}
+
+func (a *Agify) Enrich(ctx context.Context, name string) (Result, error) {
+	resp, err := a.client.Get(ctx, name)
+	if err != nil {
+		return Result{}, err
+	}
+	return a.result(resp), nil
+}
+
+func (a *Agify) EnrichBatch(ctx context.Context, names []string) (map[string]Result, error) {
+	resp, err := a.client.GetBatch(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]Result, len(names))
+	for i, name := range names {
+		results[name] = a.result(resp[i])
+	}
+	return results, nil
+}
+
+func (a *Agify) result(resp agify.Response) Result {
+	if resp.Age == 0 {
+		return Result{}
+	}
+	return Result{
+		Age: resp.Age,
+		Sources: []model.Enrichment{{
+			Attribute:   model.AttributeAge,
+			Value:       strconv.Itoa(resp.Age),
+			Provider:    a.Name(),
+			SampleCount: resp.Count,
+			FetchedAt:   time.Now(),
+		}},
+	}
+}
+
+// Genderize infers gender using genderize.io
+type Genderize struct {
+	client *genderize.Genderize
+}
+
+func NewGenderize(url string, opts client.Options) Enricher {
+	return &Genderize{client: genderize.New(url, opts)}
+}
+
+func (g *Genderize) Name() string {
+	return "genderize"
+}
+
+func (g *Genderize) Enrich(ctx context.Context, name string) (Result, error) {
+	resp, err := g.client.Get(ctx, name)
+	if err != nil {
+		return Result{}, err
+	}
+	return g.result(resp), nil
+}
+
+func (g *Genderize) EnrichBatch(ctx context.Context, names []string) (map[string]Result, error) {
+	resp, err := g.client.GetBatch(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]Result, len(names))
+	for i, name := range names {
+		results[name] = g.result(resp[i])
+	}
+	return results, nil
+}
+
+func (g *Genderize) result(resp genderize.Response) Result {
+	if resp.Gender != "male" && resp.Gender != "female" {
+		return Result{}
+	}
+	return Result{
+		Gender: resp.Gender,
+		Sources: []model.Enrichment{{
+			Attribute:   model.AttributeGender,
+			Value:       resp.Gender,
+			Provider:    g.Name(),
+			Probability: &resp.Probability,
+			SampleCount: resp.Count,
+			FetchedAt:   time.Now(),
+		}},
+	}
+}
+
+// Nationalize infers the ranked nationalities using nationalize.io
+type Nationalize struct {
+	client *nationalize.Nationalize
+}
+
+func NewNationalize(url string, opts client.Options) Enricher {
+	return &Nationalize{client: nationalize.New(url, opts)}
+}
+
+func (n *Nationalize) Name() string {
+	return "nationalize"
+}
+
+func (n *Nationalize) Enrich(ctx context.Context, name string) (Result, error) {
+	resp, err := n.client.Get(ctx, name)
+	if err != nil {
+		return Result{}, err
+	}
+	return n.result(resp), nil
+}
+
+func (n *Nationalize) EnrichBatch(ctx context.Context, names []string) (map[string]Result, error) {
+	resp, err := n.client.GetBatch(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]Result, len(names))
+	for i, name := range names {
+		results[name] = n.result(resp[i])
+	}
+	return results, nil
+}
+
+func (n *Nationalize) result(resp nationalize.Response) Result {
+	if len(resp.Country) == 0 {
+		return Result{}
+	}
+	nationalities := make([]model.Nationality, 0, len(resp.Country))
+	for _, c := range resp.Country {
+		nationalities = append(nationalities, model.Nationality{CountryID: c.CountryId, Probability: c.Probability})
+	}
+	sort.SliceStable(nationalities, func(i, j int) bool {
+		return nationalities[i].Probability > nationalities[j].Probability
+	})
+	top := nationalities[0]
+	return Result{
+		Nationality:   top.CountryID,
+		Nationalities: nationalities,
+		Sources: []model.Enrichment{{
+			Attribute:   model.AttributeNationality,
+			Value:       top.CountryID,
+			Provider:    n.Name(),
+			Probability: &top.Probability,
+			SampleCount: resp.Count,
+			FetchedAt:   time.Now(),
+		}},
+	}
+}
@@ -0,0 +1,60 @@
+package enricher
+
+import (
+	"effectiveMobile/internal/app/client"
+	"fmt"
+	"sort"
+)
+
+// Factory builds an enricher for the service at url
+type Factory func(url string, opts client.Options) Enricher
+
+// Provider is a configured enrichment provider
+type Provider struct {
+	Name string
+	URL  string
+}
+
+// Registry maps provider names to their factories
+type Registry struct {
+	factories map[string]Factory
+}
+
+// NewRegistry returns a registry with the built-in providers registered
+func NewRegistry() *Registry {
+	r := &Registry{factories: make(map[string]Factory)}
+	r.Register("agify", NewAgify)
+	r.Register("genderize", NewGenderize)
+	r.Register("nationalize", NewNationalize)
+	return r
+}
+
+// Register adds or replaces the factory for a provider name
+func (r *Registry) Register(name string, f Factory) {
+	r.factories[name] = f
+}
+
+// Build creates enrichers for the given providers in the given order
+func (r *Registry) Build(providers []Provider, opts client.Options) ([]Enricher, error) {
+	enrichers := make([]Enricher, 0, len(providers))
+	for _, p := range providers {
+		f, ok := r.factories[p.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown enrichment provider %q, known: %v", p.Name, r.names())
+		}
+		if p.URL == "" {
+			return nil, fmt.Errorf("enrichment provider %q has no URL", p.Name)
+		}
+		enrichers = append(enrichers, f(p.URL, opts))
+	}
+	return enrichers, nil
+}
+
+func (r *Registry) names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,415 @@
+// Package pipeline enriches stored humans in the background.
+package pipeline
+
+import (
+	"context"
+	"effectiveMobile/internal/app/enricher"
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/normalize"
+	"effectiveMobile/internal/store"
+	"errors"
+	"go.uber.org/zap"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Config controls the worker pool and retries of failed enrichments
+type Config struct {
+	Workers   int
+	QueueSize int
+	// MaxAttempts — сколько раз обогащение пробуется до перехода в статус failed
+	MaxAttempts  int
+	RetryWait    time.Duration
+	RetryMaxWait time.Duration
+	// Timeout ограничивает одну попытку обогащения
+	Timeout time.Duration
+	// SweepInterval — как часто подбираются записи в статусе pending,
+	// не попавшие в очередь (переполнение, перезапуск процесса)
+	SweepInterval time.Duration
+	// Записи, обогащённые раньше чем RefreshAge назад, раз в RefreshInterval
+	// возвращаются в pending порциями по RefreshBatch. Нулевой RefreshAge отключает обновление
+	RefreshAge      time.Duration
+	RefreshInterval time.Duration
+	RefreshBatch    int
+	// Scheme переводит имена в латиницу перед запросом к сервисам обогащения
+	Scheme normalize.Scheme
+}
+
+// batchSize is the number of distinct names enriched with one provider request
+const batchSize = 10
+
+// auditActor is the actor of the changes made by the pipeline
+const auditActor = "enrichment"
+
+type job struct {
+	id      int
+	attempt int
+}
+
+// Pipeline moves humans from pending to enriched or failed
+type Pipeline struct {
+	repo     store.HumanRepository
+	enricher enricher.Enricher
+	config   Config
+	logger   *zap.Logger
+
+	ctx     context.Context
+	queue   chan job
+	batches chan struct{}
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	inFlight map[int]bool
+}
+
+func New(repo store.HumanRepository, e enricher.Enricher, config Config, logger *zap.Logger) *Pipeline {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.QueueSize < 1 {
+		config.QueueSize = 1
+	}
+	return &Pipeline{
+		repo:     repo,
+		enricher: e,
+		config:   config,
+		logger:   logger,
+		ctx:      context.Background(),
+		queue:    make(chan job, config.QueueSize),
+		batches:  make(chan struct{}, config.Workers),
+		inFlight: make(map[int]bool),
+	}
+}
+
+// Start launches the workers and the sweeper; they stop when ctx is done
+func (p *Pipeline) Start(ctx context.Context) {
+	// Изменения, сделанные конвейером, попадают в историю от его имени
+	ctx = store.WithAudit(ctx, store.Audit{Actor: auditActor})
+	p.ctx = ctx
+	for i := 0; i < p.config.Workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.work(ctx)
+		}()
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.sweep(ctx)
+	}()
+	if p.config.RefreshAge > 0 && p.config.RefreshInterval > 0 {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.refresh(ctx)
+		}()
+	}
+}
+
+// Wait blocks until all workers have stopped
+func (p *Pipeline) Wait() {
+	p.wg.Wait()
+}
+
+// Enqueue schedules enrichment of a human. It never blocks: when the queue
+// is full the human stays pending and is picked up by the next sweep.
+func (p *Pipeline) Enqueue(id int) bool {
+	return p.enqueue(job{id: id, attempt: 1})
+}
+
+// EnqueueBatch enriches freshly created humans with batched provider requests,
+// asking about every distinct name once. Humans of a failed batch fall back to
+// one-by-one enrichment with retries. At most Workers batches run at a time.
+// Like Enqueue it never blocks: when every slot is busy or the pipeline has stopped
+// the humans stay pending and are picked up by the sweep.
+func (p *Pipeline) EnqueueBatch(humans []model.Human) bool {
+	if p.ctx.Err() != nil {
+		return false
+	}
+	select {
+	case p.batches <- struct{}{}:
+	default:
+		p.logger.Warn("all enrichment batch slots are busy", zap.Int("humans", len(humans)))
+		return false
+	}
+
+	p.mu.Lock()
+	for _, h := range humans {
+		p.inFlight[h.Id] = true
+	}
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.batches }()
+		p.processBatch(p.ctx, humans)
+	}()
+	return true
+}
+
+func (p *Pipeline) processBatch(ctx context.Context, humans []model.Human) {
+	byName := make(map[string][]*model.Human)
+	var names []string
+	for i := range humans {
+		name := normalize.Latin(humans[i].Name, p.config.Scheme)
+		key := enricher.NormalizeName(name)
+		if _, ok := byName[key]; !ok {
+			names = append(names, name)
+		}
+		byName[key] = append(byName[key], &humans[i])
+	}
+
+	for start := 0; start < len(names); start += batchSize {
+		chunk := names[start:min(start+batchSize, len(names))]
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if p.config.Timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		}
+		results, err := enricher.EnrichBatch(attemptCtx, p.enricher, chunk)
+		cancel()
+
+		for _, name := range chunk {
+			for _, human := range byName[enricher.NormalizeName(name)] {
+				if ctx.Err() != nil {
+					p.done(human.Id)
+					continue
+				}
+				if err != nil {
+					p.done(human.Id)
+					p.enqueue(job{id: human.Id, attempt: 1})
+					continue
+				}
+				apply(human, results[name])
+				human.EnrichmentStatus = model.EnrichmentEnriched
+				human.EnrichmentError = ""
+				p.save(ctx, human)
+				p.done(human.Id)
+			}
+		}
+		if err != nil {
+			p.logger.Warn("batch enrichment failed, falling back to single enrichment",
+				zap.Int("names", len(chunk)), zap.Error(err))
+		}
+	}
+}
+
+func (p *Pipeline) enqueue(j job) bool {
+	p.mu.Lock()
+	if p.inFlight[j.id] && j.attempt == 1 {
+		p.mu.Unlock()
+		return true
+	}
+	p.inFlight[j.id] = true
+	p.mu.Unlock()
+
+	select {
+	case p.queue <- j:
+		return true
+	default:
+		p.done(j.id)
+		p.logger.Warn("enrichment queue is full", zap.Int("id", j.id))
+		return false
+	}
+}
+
+func (p *Pipeline) done(id int) {
+	p.mu.Lock()
+	delete(p.inFlight, id)
+	p.mu.Unlock()
+}
+
+func (p *Pipeline) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-p.queue:
+			p.process(ctx, j)
+		}
+	}
+}
+
+func (p *Pipeline) process(ctx context.Context, j job) {
+	human, err := p.repo.GetHuman(ctx, j.id)
+	if err != nil {
+		p.done(j.id)
+		if !errors.Is(err, store.ErrHumanNotFound) {
+			p.logger.Error("failed to load human for enrichment", zap.Int("id", j.id), zap.Error(err))
+		}
+		return
+	}
+
+	attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+	if p.config.Timeout > 0 {
+		attemptCtx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+	}
+	res, err := p.enricher.Enrich(attemptCtx, normalize.Latin(human.Name, p.config.Scheme))
+	cancel()
+	if ctx.Err() != nil {
+		// Процесс останавливается: запись остаётся pending и будет подобрана после перезапуска
+		p.done(j.id)
+		return
+	}
+
+	if err != nil && j.attempt < p.config.MaxAttempts {
+		wait := p.backoff(j.attempt)
+		p.logger.Warn("enrichment failed, retrying",
+			zap.Int("id", j.id), zap.Int("attempt", j.attempt), zap.Duration("wait", wait), zap.Error(err))
+		time.AfterFunc(wait, func() {
+			if ctx.Err() == nil {
+				p.enqueue(job{id: j.id, attempt: j.attempt + 1})
+			}
+		})
+		return
+	}
+	defer p.done(j.id)
+
+	// При ошибке части сервисов res содержит только то, что вывели остальные
+	apply(human, res)
+	human.EnrichmentStatus = model.EnrichmentEnriched
+	human.EnrichmentError = ""
+	if err != nil {
+		human.EnrichmentStatus = model.EnrichmentFailed
+		human.EnrichmentError = err.Error()
+	}
+	if p.save(ctx, human) {
+		p.logger.Info("human enriched",
+			zap.Int("id", j.id), zap.String("status", human.EnrichmentStatus), zap.Int("attempt", j.attempt))
+	}
+}
+
+// save stores the enrichment of human and reports whether it was stored.
+// A result for a human changed in the meantime is stale and is dropped.
+func (p *Pipeline) save(ctx context.Context, human *model.Human) bool {
+	err := p.repo.SaveEnrichment(ctx, human)
+	if errors.Is(err, store.ErrVersionConflict) {
+		p.logger.Info("human changed during enrichment, result dropped",
+			zap.Int("id", human.Id), zap.Int("version", human.Version))
+		return false
+	}
+	if err != nil {
+		p.logger.Error("failed to save enrichment", zap.Int("id", human.Id), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// apply copies the attributes inferred by res to human. Attributes res lacks, because their
+// provider failed or found nothing, keep the stored values, and so do values set by hand,
+// which have no provenance in human.Enrichment.
+func apply(human *model.Human, res enricher.Result) {
+	enriched := make(map[string]bool)
+	for _, e := range human.Enrichment {
+		enriched[e.Attribute] = true
+	}
+	overwrite := func(attribute string, set bool) bool {
+		return !set || enriched[attribute]
+	}
+
+	applied := make(map[string]bool)
+	if res.Age != 0 && overwrite(model.AttributeAge, human.Age != 0) {
+		human.Age = res.Age
+		applied[model.AttributeAge] = true
+	}
+	if res.Gender != "" && overwrite(model.AttributeGender, human.Gender != "" && human.Gender != "unknown") {
+		human.Gender = res.Gender
+		applied[model.AttributeGender] = true
+	}
+	if human.Gender == "" {
+		human.Gender = "unknown"
+	}
+	if res.Nationality != "" && overwrite(model.AttributeNationality, human.Nationality != "") {
+		human.Nationality = res.Nationality
+		human.Nationalities = res.Nationalities
+		applied[model.AttributeNationality] = true
+	}
+
+	human.Enrichment = nil
+	for _, src := range res.Sources {
+		if applied[src.Attribute] {
+			human.Enrichment = append(human.Enrichment, src)
+		}
+	}
+}
+
+// backoff returns a jittered exponential delay before the next attempt
+func (p *Pipeline) backoff(attempt int) time.Duration {
+	wait := p.config.RetryWait << (attempt - 1)
+	if wait <= 0 || wait > p.config.RetryMaxWait {
+		wait = p.config.RetryMaxWait
+	}
+	half := wait / 2
+	if half <= 0 {
+		return wait
+	}
+	return half + rand.N(half)
+}
+
+func (p *Pipeline) sweep(ctx context.Context) {
+	p.enqueuePending(ctx)
+	if p.config.SweepInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(p.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.enqueuePending(ctx)
+		}
+	}
+}
+
+func (p *Pipeline) enqueuePending(ctx context.Context) {
+	ids, err := p.repo.GetHumanIDsByStatus(ctx, model.EnrichmentPending, cap(p.queue))
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error("failed to list pending humans", zap.Error(err))
+		}
+		return
+	}
+	p.Requeue(ids)
+}
+
+// Requeue schedules enrichment of humans already moved back to pending
+func (p *Pipeline) Requeue(ids []int) {
+	for _, id := range ids {
+		if !p.Enqueue(id) {
+			// Остальные записи подберёт sweep
+			return
+		}
+	}
+}
+
+// refresh periodically re-enriches humans whose enrichment is stale
+func (p *Pipeline) refresh(ctx context.Context) {
+	ticker := time.NewTicker(p.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ids, _, err := p.repo.ResetEnrichments(ctx, "", time.Now().Add(-p.config.RefreshAge), p.config.RefreshBatch)
+			if err != nil {
+				if ctx.Err() == nil {
+					p.logger.Error("failed to reset stale enrichments", zap.Error(err))
+				}
+				continue
+			}
+			if len(ids) > 0 {
+				p.logger.Info("refreshing stale enrichments", zap.Int("count", len(ids)))
+			}
+			p.Requeue(ids)
+		}
+	}
+}
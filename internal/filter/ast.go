@@ -0,0 +1,93 @@
+// Package filter parses filter expressions such as
+//
+//	nationality in ('RU', 'KZ') and (age >= 18 or gender != unknown) and patronymic is null
+//
+// into an AST checked against a set of typed fields.
+package filter
+
+import "fmt"
+
+// Type is the type of a filterable field
+type Type int
+
+const (
+	String Type = iota
+	Int
+)
+
+// Field describes a filterable field
+type Field struct {
+	Type Type
+	// Values, when set, lists the only values the field may be compared with
+	Values []string
+}
+
+// Fields maps the names that may appear in an expression to their descriptions
+type Fields map[string]Field
+
+// Expr is a node of a parsed expression
+type Expr interface {
+	expr()
+}
+
+// Logical joins two expressions with "and" or "or"
+type Logical struct {
+	Op          string
+	Left, Right Expr
+}
+
+// Not negates an expression
+type Not struct {
+	X Expr
+}
+
+// Compare compares a field with a value; Op is one of = != < <= > >= like
+type Compare struct {
+	Field string
+	Op    string
+	Value Value
+}
+
+// In checks that a field equals one of the values
+type In struct {
+	Field  string
+	Not    bool
+	Values []Value
+}
+
+// IsNull checks that a field is (or, with Not, is not) null
+type IsNull struct {
+	Field string
+	Not   bool
+}
+
+func (*Logical) expr() {}
+func (*Not) expr()     {}
+func (*Compare) expr() {}
+func (*In) expr()      {}
+func (*IsNull) expr()  {}
+
+// Value is a literal converted to the type of the field it is compared with
+type Value struct {
+	Str  string
+	Int  int
+	Type Type
+}
+
+// Any returns the value as a query argument
+func (v Value) Any() interface{} {
+	if v.Type == Int {
+		return v.Int
+	}
+	return v.Str
+}
+
+// Error is a syntax or type error; Pos is the 1-based character position in the expression
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Msg)
+}
@@ -0,0 +1,117 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// pos — позиция первого символа токена, начиная с 1
+	pos int
+}
+
+// is reports whether the token is the given keyword, ignoring case
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return "string '" + t.text + "'"
+	default:
+		return "'" + t.text + "'"
+	}
+}
+
+// lex splits the expression into tokens; positions count characters, not bytes
+func lex(s string) ([]token, error) {
+	src := []rune(s)
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		start := i
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: start + 1})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: start + 1})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: start + 1})
+			i++
+		case c == '=':
+			tokens = append(tokens, token{kind: tokenOperator, text: "=", pos: start + 1})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(src) && (src[i+1] == '=' || c == '<' && src[i+1] == '>') {
+				op += string(src[i+1])
+			}
+			if op == "!" {
+				return nil, &Error{Pos: start + 1, Msg: "expected '!='"}
+			}
+			i += len(op)
+			if op == "<>" {
+				op = "!="
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start + 1})
+		case c == '\'':
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(src) {
+					return nil, &Error{Pos: start + 1, Msg: "unterminated string"}
+				}
+				if src[i] == '\'' {
+					// Кавычка внутри строки удваивается, как в SQL
+					if i+1 < len(src) && src[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(src[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start + 1})
+		case unicode.IsDigit(c) || c == '-' && i+1 < len(src) && unicode.IsDigit(src[i+1]):
+			i++
+			for i < len(src) && unicode.IsDigit(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(src[start:i]), pos: start + 1})
+		case unicode.IsLetter(c) || c == '_':
+			for i < len(src) && (unicode.IsLetter(src[i]) || unicode.IsDigit(src[i]) || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(src[start:i]), pos: start + 1})
+		default:
+			return nil, &Error{Pos: start + 1, Msg: "unexpected character '" + string(c) + "'"}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src) + 1}), nil
+}
@@ -0,0 +1,243 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxDepth ограничивает вложенность скобок и not, чтобы разбор не уходил в глубокую рекурсию
+const maxDepth = 32
+
+// Parse parses an expression over the given fields.
+//
+//	expr       = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field ( op value | [ "not" ] "in" "(" value { "," value } ")"
+//	           | "is" [ "not" ] "null" | [ "not" ] "like" value )
+//	op         = "=" | "!=" | "<>" | "<" | "<=" | ">" | ">="
+//	value      = 'quoted string' | number | word
+//
+// Keywords are case-insensitive. Errors are returned as *Error.
+func Parse(s string, fields Fields) (Expr, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fields: fields}
+	if p.peek().kind == tokenEOF {
+		return nil, &Error{Pos: 1, Msg: "empty expression"}
+	}
+	e, err := p.or(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &Error{Pos: t.pos, Msg: "unexpected " + t.describe() + ", expected 'and', 'or' or end of expression"}
+	}
+	return e, nil
+}
+
+type parser struct {
+	tokens []token
+	fields Fields
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) or(depth int) (Expr, error) {
+	left, err := p.and(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("or") {
+		p.next()
+		right, err := p.and(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and(depth int) (Expr, error) {
+	left, err := p.unary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("and") {
+		p.next()
+		right, err := p.unary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary(depth int) (Expr, error) {
+	t := p.peek()
+	if depth >= maxDepth {
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("expression is nested deeper than %d levels", maxDepth)}
+	}
+	switch {
+	case t.is("not"):
+		p.next()
+		x, err := p.unary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	case t.kind == tokenLParen:
+		p.next()
+		e, err := p.or(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, &Error{Pos: t.pos, Msg: "unexpected " + t.describe() + ", expected ')'"}
+		}
+		return e, nil
+	default:
+		return p.comparison()
+	}
+}
+
+func (p *parser) comparison() (Expr, error) {
+	t := p.next()
+	if t.kind != tokenWord {
+		return nil, &Error{Pos: t.pos, Msg: "unexpected " + t.describe() + ", expected field name"}
+	}
+	field := strings.ToLower(t.text)
+	f, ok := p.fields[field]
+	if !ok {
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("unknown field %q, expected one of %s", t.text, p.fieldNames())}
+	}
+
+	op := p.next()
+	switch {
+	case op.kind == tokenOperator:
+		v, err := p.value(f)
+		if err != nil {
+			return nil, err
+		}
+		return &Compare{Field: field, Op: op.text, Value: v}, nil
+	case op.is("is"):
+		not := p.peek().is("not")
+		if not {
+			p.next()
+		}
+		if t := p.next(); !t.is("null") {
+			return nil, &Error{Pos: t.pos, Msg: "unexpected " + t.describe() + ", expected 'null'"}
+		}
+		return &IsNull{Field: field, Not: not}, nil
+	case op.is("not"):
+		next := p.next()
+		switch {
+		case next.is("in"):
+			return p.in(field, f, true)
+		case next.is("like"):
+			return p.like(field, f, next, true)
+		}
+		return nil, &Error{Pos: next.pos, Msg: "unexpected " + next.describe() + ", expected 'in' or 'like'"}
+	case op.is("in"):
+		return p.in(field, f, false)
+	case op.is("like"):
+		return p.like(field, f, op, false)
+	}
+	return nil, &Error{Pos: op.pos, Msg: "unexpected " + op.describe() + ", expected operator, 'in', 'like' or 'is'"}
+}
+
+func (p *parser) in(field string, f Field, not bool) (Expr, error) {
+	if t := p.next(); t.kind != tokenLParen {
+		return nil, &Error{Pos: t.pos, Msg: "unexpected " + t.describe() + ", expected '('"}
+	}
+	e := &In{Field: field, Not: not}
+	for {
+		v, err := p.value(f)
+		if err != nil {
+			return nil, err
+		}
+		e.Values = append(e.Values, v)
+		t := p.next()
+		if t.kind == tokenRParen {
+			return e, nil
+		}
+		if t.kind != tokenComma {
+			return nil, &Error{Pos: t.pos, Msg: "unexpected " + t.describe() + ", expected ',' or ')'"}
+		}
+	}
+}
+
+func (p *parser) like(field string, f Field, op token, not bool) (Expr, error) {
+	if f.Type != String || len(f.Values) > 0 {
+		return nil, &Error{Pos: op.pos, Msg: fmt.Sprintf("'like' is not supported for field %q", field)}
+	}
+	v, err := p.value(f)
+	if err != nil {
+		return nil, err
+	}
+	var e Expr = &Compare{Field: field, Op: "like", Value: v}
+	if not {
+		e = &Not{X: e}
+	}
+	return e, nil
+}
+
+// value reads a literal and converts it to the type of f
+func (p *parser) value(f Field) (Value, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString, tokenWord, tokenNumber:
+	default:
+		return Value{}, &Error{Pos: t.pos, Msg: "unexpected " + t.describe() + ", expected value"}
+	}
+	if t.kind == tokenWord && (t.is("null") || t.is("and") || t.is("or") || t.is("not")) {
+		return Value{}, &Error{Pos: t.pos, Msg: "unexpected " + t.describe() + ", expected value; use 'is null' to check for null"}
+	}
+
+	if f.Type == String {
+		if len(f.Values) > 0 && !contains(f.Values, t.text) {
+			return Value{}, &Error{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s, expected one of %s", t.describe(), strings.Join(f.Values, ", "))}
+		}
+		return Value{Str: t.text, Type: String}, nil
+	}
+	n, err := strconv.Atoi(t.text)
+	if err != nil {
+		return Value{}, &Error{Pos: t.pos, Msg: "expected integer, got " + t.describe()}
+	}
+	return Value{Int: n, Type: Int}, nil
+}
+
+func (p *parser) fieldNames() string {
+	names := make([]string, 0, len(p.fields))
+	for name := range p.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
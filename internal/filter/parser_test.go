@@ -0,0 +1,180 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var testFields = Fields{
+	"name":        {Type: String},
+	"surname":     {Type: String},
+	"patronymic":  {Type: String},
+	"nationality": {Type: String},
+	"gender":      {Type: String, Values: []string{"male", "female", "unknown"}},
+	"age":         {Type: Int},
+}
+
+// render prints an expression with explicit parentheses to compare parse results
+func render(e Expr) string {
+	switch e := e.(type) {
+	case *Logical:
+		return "(" + render(e.Left) + " " + e.Op + " " + render(e.Right) + ")"
+	case *Not:
+		return "not(" + render(e.X) + ")"
+	case *Compare:
+		return e.Field + " " + e.Op + " " + renderValue(e.Value)
+	case *In:
+		values := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			values[i] = renderValue(v)
+		}
+		op := " in "
+		if e.Not {
+			op = " not in "
+		}
+		return e.Field + op + "(" + strings.Join(values, ", ") + ")"
+	case *IsNull:
+		if e.Not {
+			return e.Field + " is not null"
+		}
+		return e.Field + " is null"
+	default:
+		return fmt.Sprintf("%T", e)
+	}
+}
+
+func renderValue(v Value) string {
+	if v.Type == Int {
+		return fmt.Sprint(v.Int)
+	}
+	return fmt.Sprintf("%q", v.Str)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		// операторы сравнения
+		{"age >= 18", "age >= 18"},
+		{"age <> 3", "age != 3"},
+		{"age != 3", "age != 3"},
+		{"age<3", "age < 3"},
+		{"age > -1", "age > -1"},
+		{"age = '1'", "age = 1"},
+		{"gender = male", `gender = "male"`},
+		{"Name = Ivan", `name = "Ivan"`},
+
+		// приоритет: not сильнее and, and сильнее or
+		{"name = a or surname = b and age = 1", `(name = "a" or (surname = "b" and age = 1))`},
+		{"name = a and surname = b or age = 1", `((name = "a" and surname = "b") or age = 1)`},
+		{"(name = a or surname = b) and age = 1", `((name = "a" or surname = "b") and age = 1)`},
+		{"not name = a and age = 1", `(not(name = "a") and age = 1)`},
+		{"NOT (age < 1 OR age > 2)", "not((age < 1 or age > 2))"},
+		{"not not age = 1", "not(not(age = 1))"},
+		{"age = 1 and age = 2 and age = 3", "((age = 1 and age = 2) and age = 3)"},
+
+		// in, like и is null
+		{"nationality in ('RU', 'KZ')", `nationality in ("RU", "KZ")`},
+		{"age not in (1,2)", "age not in (1, 2)"},
+		{"gender IN (male)", `gender in ("male")`},
+		{"patronymic is null", "patronymic is null"},
+		{"patronymic IS NOT NULL", "patronymic is not null"},
+		{"name like 'Iv%'", `name like "Iv%"`},
+		{"name not like 'Iv%'", `not(name like "Iv%")`},
+
+		// строки
+		{"name = 'O''Neil'", `name = "O'Neil"`},
+		{"name = ''", `name = ""`},
+		{"name = 'Пётр Ильич'", `name = "Пётр Ильич"`},
+		{"name = 'and'", `name = "and"`},
+		{"name = '12'", `name = "12"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr, testFields)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+			}
+			if got := render(e); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{"", 1, "empty expression"},
+		{"   ", 1, "empty expression"},
+		{"foo = 1", 1, `unknown field "foo"`},
+		{"age >", 6, "expected value"},
+		{"age = x", 7, "expected integer"},
+		{"gender = other", 10, "expected one of male, female, unknown"},
+		{"name = 'abc", 8, "unterminated string"},
+		{"age ! 1", 5, "expected '!='"},
+		{"name = a and", 13, "expected field name"},
+		{"(age = 1", 9, "expected ')'"},
+		{"age = 1)", 8, "expected 'and', 'or' or end of expression"},
+		{"age = 1 age = 2", 9, "expected 'and', 'or' or end of expression"},
+		{"name = null", 8, "use 'is null'"},
+		{"age is 1", 8, "expected 'null'"},
+		{"age not between", 9, "expected 'in' or 'like'"},
+		{"age between 1", 5, "expected operator"},
+		{"age in 1", 8, "expected '('"},
+		{"age in (1 2)", 11, "expected ',' or ')'"},
+		{"age in ()", 9, "expected value"},
+		{"age like 1", 5, "'like' is not supported"},
+		{"gender not like m", 12, "'like' is not supported"},
+		// позиции считаются в символах, а не в байтах
+		{"name = 'Пётр' and @", 19, "unexpected character '@'"},
+		{"name = 'ё' or возраст = 1", 15, `unknown field "возраст"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr, testFields)
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("Parse(%q) error = %v, want *Error", tt.expr, err)
+			}
+			if e.Pos != tt.pos || !strings.Contains(e.Msg, tt.msg) {
+				t.Errorf("Parse(%q) error = %q at %d, want %q at %d", tt.expr, e.Msg, e.Pos, tt.msg, tt.pos)
+			}
+		})
+	}
+}
+
+func TestParseDepth(t *testing.T) {
+	nested := func(n int) string {
+		return strings.Repeat("(", n) + "age = 1" + strings.Repeat(")", n)
+	}
+	if _, err := Parse(nested(maxDepth-1), testFields); err != nil {
+		t.Errorf("%d nested parentheses: %v", maxDepth-1, err)
+	}
+	if _, err := Parse(strings.Repeat("not ", maxDepth-1)+"age = 1", testFields); err != nil {
+		t.Errorf("%d nested not: %v", maxDepth-1, err)
+	}
+
+	for _, expr := range []string{
+		nested(maxDepth),
+		strings.Repeat("not ", maxDepth) + "age = 1",
+		nested(10000),
+	} {
+		_, err := Parse(expr, testFields)
+		var e *Error
+		if !errors.As(err, &e) || !strings.Contains(e.Msg, "nested deeper than 32 levels") {
+			t.Errorf("Parse of %d characters: error = %v, want depth error", len(expr), err)
+		}
+	}
+
+	_, err := Parse(nested(maxDepth), testFields)
+	if e := (*Error)(nil); errors.As(err, &e) && e.Pos != maxDepth+1 {
+		t.Errorf("depth error at %d, want %d", e.Pos, maxDepth+1)
+	}
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a malformed pagination cursor
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SortField orders humans by a column
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// sortableColumns are the HumanFilter fields humans can be sorted by
+var sortableColumns = map[string]bool{
+	"id":          true,
+	"name":        true,
+	"surname":     true,
+	"patronymic":  true,
+	"age":         true,
+	"gender":      true,
+	"nationality": true,
+	"created_at":  true,
+	"updated_at":  true,
+}
+
+// ParseSort parses a comma-separated list of columns, each optionally prefixed
+// with "-" for descending order, e.g. "age,-surname"
+func ParseSort(s string) ([]SortField, error) {
+	var sort []SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		f := SortField{Column: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !sortableColumns[f.Column] {
+			return nil, fmt.Errorf("cannot sort by %q", f.Column)
+		}
+		if seen[f.Column] {
+			return nil, fmt.Errorf("%q is listed twice", f.Column)
+		}
+		seen[f.Column] = true
+		sort = append(sort, f)
+	}
+	return sort, nil
+}
+
+// DefaultSort is the order of humans in cursor mode when no sort is given: newest first
+var DefaultSort = []SortField{{Column: "id", Desc: true}}
+
+// WithTieBreaker appends id to sort unless it is already there, making the order total
+func WithTieBreaker(sort []SortField) []SortField {
+	for _, f := range sort {
+		if f.Column == "id" {
+			return sort
+		}
+	}
+	return append(append([]SortField(nil), sort...), SortField{Column: "id"})
+}
+
+// FormatSort renders sort as a query parameter value, e.g. "age,-surname"
+func FormatSort(sort []SortField) string {
+	parts := make([]string, len(sort))
+	for i, f := range sort {
+		parts[i] = f.Column
+		if f.Desc {
+			parts[i] = "-" + f.Column
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Cursor is the position after the last human of a page in keyset pagination
+type Cursor struct {
+	// Sort is the sort the cursor was issued for, see FormatSort
+	Sort string `json:"s"`
+	// Values are the sort key values of the last human, one per sort field
+	Values []string `json:"v"`
+}
+
+// NewCursor builds the cursor pointing after h for the given sort
+func NewCursor(h *Human, sort []SortField) *Cursor {
+	c := &Cursor{Sort: FormatSort(sort), Values: make([]string, len(sort))}
+	for i, f := range sort {
+		c.Values[i] = h.sortValue(f.Column)
+	}
+	return c
+}
+
+// Encode returns the opaque token sent to clients
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by Encode and checks that it was issued for sort
+func DecodeCursor(token string, sort []SortField) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != FormatSort(sort) || len(c.Values) != len(sort) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+func (h *Human) sortValue(column string) string {
+	switch column {
+	case "id":
+		return strconv.Itoa(h.Id)
+	case "name":
+		return h.Name
+	case "surname":
+		return h.Surname
+	case "patronymic":
+		return h.Patronymic
+	case "age":
+		return strconv.Itoa(h.Age)
+	case "gender":
+		return h.Gender
+	case "nationality":
+		return h.Nationality
+	case "created_at":
+		return h.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return h.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return ""
+	}
+}
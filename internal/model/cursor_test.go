@@ -0,0 +1,130 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []SortField
+	}{
+		{"age", []SortField{{Column: "age"}}},
+		{"-age", []SortField{{Column: "age", Desc: true}}},
+		{"age, -surname ,name", []SortField{{Column: "age"}, {Column: "surname", Desc: true}, {Column: "name"}}},
+		{"-updated_at,id", []SortField{{Column: "updated_at", Desc: true}, {Column: "id"}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSort(tt.in)
+		if err != nil {
+			t.Errorf("ParseSort(%q) error: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSort(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if s := FormatSort(got); s != FormatSort(tt.want) {
+			t.Errorf("FormatSort(ParseSort(%q)) = %q", tt.in, s)
+		}
+	}
+}
+
+func TestParseSortRejects(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"age,",
+		"enrichment_status",
+		"search_key",
+		"deleted_at",
+		"age; DROP TABLE people",
+		`"age"`,
+		"Age",
+		"--age",
+		"+age",
+		"age,-age",
+		"id,id",
+	} {
+		if got, err := ParseSort(in); err == nil {
+			t.Errorf("ParseSort(%q) = %+v, want error", in, got)
+		}
+	}
+}
+
+func TestWithTieBreaker(t *testing.T) {
+	tests := []struct {
+		in, want []SortField
+	}{
+		{nil, []SortField{{Column: "id"}}},
+		{[]SortField{{Column: "age", Desc: true}}, []SortField{{Column: "age", Desc: true}, {Column: "id"}}},
+		{[]SortField{{Column: "id", Desc: true}}, []SortField{{Column: "id", Desc: true}}},
+		{[]SortField{{Column: "id"}, {Column: "age"}}, []SortField{{Column: "id"}, {Column: "age"}}},
+	}
+	for _, tt := range tests {
+		if got := WithTieBreaker(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WithTieBreaker(%+v) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	// Исходный срез не меняется, даже если в нём есть место для id
+	sort := make([]SortField, 1, 2)
+	sort[0] = SortField{Column: "age"}
+	WithTieBreaker(sort)
+	if extended := sort[:2]; extended[1] != (SortField{}) {
+		t.Errorf("WithTieBreaker wrote into the backing array of its argument: %+v", extended)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2025, 5, 25, 12, 0, 0, 123456789, time.UTC)
+	h := &Human{Id: 42, Name: "Иван", Surname: "O'Neil", Age: 30, CreatedAt: created}
+	sort := WithTieBreaker([]SortField{{Column: "surname", Desc: true}, {Column: "created_at"}})
+
+	c := NewCursor(h, sort)
+	want := []string{"O'Neil", "2025-05-25T12:00:00.123456789Z", "42"}
+	if !reflect.DeepEqual(c.Values, want) {
+		t.Errorf("NewCursor values = %q, want %q", c.Values, want)
+	}
+
+	decoded, err := DecodeCursor(c.Encode(), sort)
+	if err != nil {
+		t.Fatalf("DecodeCursor error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, c) {
+		t.Errorf("DecodeCursor = %+v, want %+v", decoded, c)
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	sort := WithTieBreaker([]SortField{{Column: "age"}})
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	valid := NewCursor(&Human{Id: 1, Age: 30}, sort).Encode()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"age,id","v":["30","1"]}`))},
+		{"not JSON", encode("age,id|30|1")},
+		{"wrong JSON types", encode(`{"s":"age,id","v":[30,1]}`)},
+		{"issued for another sort", NewCursor(&Human{Id: 1, Age: 30}, WithTieBreaker([]SortField{{Column: "age", Desc: true}})).Encode()},
+		{"issued for another column", NewCursor(&Human{Id: 1, Name: "a"}, WithTieBreaker([]SortField{{Column: "name"}})).Encode()},
+		{"sort rewritten", encode(`{"s":"-age,id","v":["30","1"]}`)},
+		{"value missing", encode(`{"s":"age,id","v":["30"]}`)},
+		{"extra value", encode(`{"s":"age,id","v":["30","1","2"]}`)},
+		{"truncated", valid[:len(valid)-4]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := DecodeCursor(tt.token, sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor = %+v, %v, want ErrInvalidCursor", c, err)
+			}
+		})
+	}
+}
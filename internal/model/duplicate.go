@@ -0,0 +1,8 @@
+package model
+
+// DuplicateCluster is a group of humans that probably describe the same person
+type DuplicateCluster struct {
+	// Score is the highest similarity of the search keys of two humans in the cluster, from 0 to 1
+	Score  float64 `json:"score" example:"0.72"`
+	Humans []Human `json:"humans"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EnrichmentCacheEntry is a cached provider response for a normalized name
+type EnrichmentCacheEntry struct {
+	Provider  string
+	Name      string
+	Result    json.RawMessage
+	FetchedAt time.Time
+}
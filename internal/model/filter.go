@@ -0,0 +1,15 @@
+package model
+
+import "effectiveMobile/internal/filter"
+
+// FilterFields are the fields available in the filter expression of HumanFilter
+var FilterFields = filter.Fields{
+	"id":                {Type: filter.Int},
+	"name":              {Type: filter.String},
+	"surname":           {Type: filter.String},
+	"patronymic":        {Type: filter.String},
+	"age":               {Type: filter.Int},
+	"gender":            {Type: filter.String, Values: []string{"male", "female", "unknown"}},
+	"nationality":       {Type: filter.String},
+	"enrichment_status": {Type: filter.String, Values: []string{EnrichmentPending, EnrichmentEnriched, EnrichmentFailed}},
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// History actions of a human
+const (
+	HistoryInsert  = "insert"
+	HistoryUpdate  = "update"
+	HistoryDelete  = "delete"
+	HistoryRestore = "restore"
+	// HistoryPurge is the final removal of a deleted or merged human
+	HistoryPurge = "purge"
+)
+
+// HistoryEntry is one change of a human. An update holds only the fields that changed,
+// an insert has no old values and a purge no new ones.
+type HistoryEntry struct {
+	ID        int64                  `json:"id" example:"42"`
+	HumanID   int                    `json:"human_id" example:"1"`
+	Action    string                 `json:"action" example:"update"`
+	OldValues map[string]interface{} `json:"old_values,omitempty" swaggertype:"object"`
+	NewValues map[string]interface{} `json:"new_values,omitempty" swaggertype:"object"`
+	// Actor is taken from the X-Actor header; background enrichment uses "enrichment"
+	Actor     string    `json:"actor" example:"alice"`
+	RequestID string    `json:"request_id" example:"host/abcdef-000001"`
+	ChangedAt time.Time `json:"changed_at" example:"2025-05-25T12:00:00Z"`
+}
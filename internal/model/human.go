@@ -1,5 +1,10 @@
 package model
 
+import (
+	"effectiveMobile/internal/filter"
+	"time"
+)
+
 type Human struct {
 	Id          int    `json:"id" db:"omitempty" example:"1"`
 	Name        string `json:"name" db:"name" example:"John"`
@@ -8,6 +13,69 @@ type Human struct {
 	Age         int    `json:"age" db:"age" example:"25"`
 	Gender      string `json:"gender" db:"gender" example:"male"`
 	Nationality string `json:"nationality" db:"nationality" example:"RU"`
+	// Version grows with every change of the human and is sent as its ETag
+	Version int `json:"version" db:"version" example:"3"`
+
+	EnrichmentStatus string     `json:"enrichment_status" db:"enrichment_status" example:"enriched"`
+	EnrichmentError  string     `json:"enrichment_error,omitempty" db:"enrichment_error" example:""`
+	EnrichedAt       *time.Time `json:"enriched_at,omitempty" db:"enriched_at" example:"2025-05-25T12:00:00Z"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at" example:"2025-05-25T12:00:00Z"`
+	// UpdatedAt changes with every change of the human, including enrichment
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" example:"2025-05-25T12:00:00Z"`
+	// DeletedAt is set for humans that were deleted and can still be restored
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at" example:"2025-05-25T12:00:00Z"`
+
+	Nationalities []Nationality `json:"nationalities,omitempty" db:"-"`
+	Enrichment    []Enrichment  `json:"enrichment,omitempty" db:"-"`
+}
+
+// HumanPatch holds the new values of the fields of a human to change; nil fields are kept.
+// A pointer to a zero value clears the field.
+type HumanPatch struct {
+	Name        *string
+	Surname     *string
+	Patronymic  *string
+	Age         *int
+	Gender      *string
+	Nationality *string
+}
+
+// Nationality is a candidate country of a human, ranked by probability
+type Nationality struct {
+	CountryID   string  `json:"country_id" example:"RU"`
+	Probability float64 `json:"probability" example:"0.74"`
+}
+
+// Enrichment describes where an inferred attribute of a human came from
+type Enrichment struct {
+	Attribute   string    `json:"attribute" example:"gender"`
+	Value       string    `json:"value" example:"male"`
+	Provider    string    `json:"provider" example:"genderize"`
+	Probability *float64  `json:"probability,omitempty" example:"0.98"`
+	SampleCount int       `json:"sample_count" example:"1234"`
+	FetchedAt   time.Time `json:"fetched_at" example:"2025-05-25T12:00:00Z"`
+}
+
+// Enrichment statuses of a human
+const (
+	EnrichmentPending  = "pending"
+	EnrichmentEnriched = "enriched"
+	EnrichmentFailed   = "failed"
+)
+
+// Enriched attribute names
+const (
+	AttributeAge         = "age"
+	AttributeGender      = "gender"
+	AttributeNationality = "nationality"
+)
+
+// SearchHit is a human found by fuzzy search
+type SearchHit struct {
+	Human
+	// Score is the best trigram similarity of the name parts to the query, from 0 to 1
+	Score float64 `json:"score" example:"0.64"`
 }
 
 type HumanFilter struct {
@@ -19,6 +87,28 @@ type HumanFilter struct {
 	MaxAge      int
 	Gender      string
 	Nationality string
+	// NationalityAny matches any candidate country whose probability
+	// is at least NationalityMinProbability
+	NationalityAny            []string
+	NationalityMinProbability float64
+	EnrichmentStatus          string
+	// IncludeDeleted also returns humans that were deleted but not purged yet
+	IncludeDeleted bool
+	// CreatedAfter, CreatedBefore, UpdatedSince and UpdatedBefore bound the timestamps
+	// of humans when non-zero; the lower bound of updates is inclusive for incremental sync
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedSince  time.Time
+	UpdatedBefore time.Time
+
+	// Expr is a parsed filter expression over FilterFields, combined with the other fields by AND
+	Expr filter.Expr
+
+	// Sort orders the result; the order is unspecified when empty
+	Sort []SortField
+	// After switches to keyset pagination: the page starts after the cursor
+	// and Page is ignored. Sort must end with the id tie-breaker.
+	After *Cursor
 
 	Page     int
 	PageSize int
@@ -0,0 +1,60 @@
+// Package normalize cleans up name input and builds script-independent
+// search keys so that "Дмитрий" and "Dmitriy" are matched with each other.
+package normalize
+
+import (
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+)
+
+var folder = cases.Fold()
+
+// Trim composes the string to NFC, trims it and collapses inner whitespace to single spaces
+func Trim(s string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(s)), " ")
+}
+
+// Fold trims s and applies Unicode case folding, for comparisons that ignore case
+func Fold(s string) string {
+	return folder.String(Trim(s))
+}
+
+// SearchKey builds the key stored with a human for matching across scripts:
+// every part is folded, transliterated to Latin by ICAO and reduced so that
+// common spellings of the same name coincide, e.g. Дмитрий, Dmitriy and Dmitrij give "dmitri"
+func SearchKey(parts ...string) string {
+	keys := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if k := reduce(Transliterate(Fold(part), ICAO)); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return strings.Join(keys, " ")
+}
+
+// reductions сводят распространённые варианты латинского написания к одному
+var reductions = strings.NewReplacer(
+	"kh", "h",
+	"x", "ks",
+	"ye", "e",
+	"yo", "e",
+	"y", "i",
+	"j", "i",
+	"'", "",
+	"`", "",
+)
+
+// reduce maps Latin spelling variants to one form and drops repeated letters
+func reduce(s string) string {
+	s = reductions.Replace(s)
+	var sb strings.Builder
+	var prev rune
+	for _, r := range s {
+		if r != prev {
+			sb.WriteRune(r)
+		}
+		prev = r
+	}
+	return sb.String()
+}
@@ -0,0 +1,72 @@
+package normalize
+
+import "testing"
+
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  Иван  ", "Иван"},
+		{"Анна \t Мария\n", "Анна Мария"},
+		// NFD собирается в NFC
+		{"И\u0306ван", "Йван"},
+		{"Jose\u0301", "José"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Trim(tt.in); got != tt.want {
+			t.Errorf("Trim(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  ИВАН  ", "иван"},
+		{"ЁЛКА", "ёлка"},
+		{"И\u0306", "й"},
+		{"Straße", "strasse"},
+		{"O'NEIL", "o'neil"},
+	}
+	for _, tt := range tests {
+		if got := Fold(tt.in); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSearchKey(t *testing.T) {
+	// Распространённые написания одного имени дают один ключ
+	tests := []struct {
+		want     string
+		spelling []string
+	}{
+		{"dmitri", []string{"Дмитрий", "Dmitriy", "Dmitrij", "DMITRII", " дмитрий "}},
+		{"fedor", []string{"Фёдор", "Федор", "Fyodor", "Fedor"}},
+		{"artem", []string{"Артём", "Artyom", "Artem"}},
+		{"aleksei", []string{"Алексей", "Alexey", "Aleksei"}},
+		{"ksenia", []string{"Ксения", "Xenia", "Ksenia"}},
+		{"iulia", []string{"Юлия", "Yulia", "Julia"}},
+		{"natalia", []string{"Наталья", "Natalya", "Natalia"}},
+		{"habibulin", []string{"Хабибуллин", "Habibullin", "Khabibullin"}},
+		{"oneil", []string{"O'Neil", "O`Neil", "ONeil"}},
+	}
+	for _, tt := range tests {
+		for _, s := range tt.spelling {
+			if got := SearchKey(s); got != tt.want {
+				t.Errorf("SearchKey(%q) = %q, want %q", s, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestSearchKeyParts(t *testing.T) {
+	if got := SearchKey("Иван", "", "  ", "Петров"); got != "ivan petrov" {
+		t.Errorf("SearchKey skips empty parts: got %q", got)
+	}
+	if got := SearchKey(); got != "" {
+		t.Errorf("SearchKey() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,80 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scheme maps lowercase Cyrillic letters to their Latin transliteration
+type Scheme map[rune]string
+
+// ICAO is the transliteration of ICAO Doc 9303 used in Russian passports since 2014
+var ICAO = Scheme{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia",
+	// украинские и белорусские буквы
+	'і': "i", 'ї': "i", 'є': "ie", 'ґ': "g", 'ў': "u",
+}
+
+// GOST is system B of GOST 7.79-2000
+var GOST = Scheme{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "x", 'ц': "cz",
+	'ч': "ch", 'ш': "sh", 'щ': "shh", 'ъ': "``", 'ы': "y'", 'ь': "`", 'э': "e`", 'ю': "yu",
+	'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g`", 'ў': "u`",
+}
+
+// Schemes lists the schemes by their configuration names
+var Schemes = map[string]Scheme{
+	"icao": ICAO,
+	"gost": GOST,
+}
+
+// Transliterate replaces Cyrillic letters of s using scheme and keeps the other characters.
+// An uppercase letter gets a capitalized replacement, or a fully uppercase one
+// when the neighbouring letter is uppercase too, so "ЩУКА" becomes "SHCHUKA" and "Щука" "Shchuka".
+func Transliterate(s string, scheme Scheme) string {
+	if len(scheme) == 0 {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i, r := range s {
+		lower := unicode.ToLower(r)
+		latin, ok := scheme[lower]
+		if !ok {
+			sb.WriteRune(r)
+			continue
+		}
+		if lower == r || latin == "" {
+			sb.WriteString(latin)
+			continue
+		}
+		next, _ := utf8.DecodeRuneInString(s[i+utf8.RuneLen(r):])
+		if unicode.IsUpper(next) || !unicode.IsLetter(next) && i > 0 && isUpperBefore(s[:i]) {
+			sb.WriteString(strings.ToUpper(latin))
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(latin)
+		sb.WriteRune(unicode.ToUpper(first))
+		sb.WriteString(latin[size:])
+	}
+	return sb.String()
+}
+
+// isUpperBefore reports whether the letter right before the end of s is uppercase
+func isUpperBefore(s string) bool {
+	prev, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.IsUpper(prev)
+}
+
+// Latin returns name trimmed and transliterated with scheme, as sent to the enrichment services
+func Latin(name string, scheme Scheme) string {
+	return Transliterate(Trim(name), scheme)
+}
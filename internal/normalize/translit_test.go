@@ -0,0 +1,56 @@
+package normalize
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		in         string
+		icao, gost string
+	}{
+		{"Дмитрий", "Dmitrii", "Dmitrij"},
+		{"Йошкар", "Ioshkar", "Joshkar"},
+		{"Щукин", "Shchukin", "Shhukin"},
+		{"Хабаров", "Khabarov", "Xabarov"},
+		{"Цой", "Tsoi", "Czoj"},
+		{"Юлия", "Iuliia", "Yuliya"},
+		{"Эмма", "Emma", "E`mma"},
+		// ё, ъ и ь
+		{"Пётр", "Petr", "Pyotr"},
+		{"Ёж", "Ezh", "Yozh"},
+		{"Объедков", "Obieedkov", "Ob``edkov"},
+		{"Ильич", "Ilich", "Il`ich"},
+		{"Наталья", "Natalia", "Natal`ya"},
+		{"Мышь", "Mysh", "My'sh`"},
+		// регистр замены следует за соседними буквами
+		{"ЩУКА", "SHCHUKA", "SHHUKA"},
+		{"ЁЖ", "EZH", "YOZH"},
+		{"ЛЬВОВ", "LVOV", "L`VOV"},
+		{"Я", "Ia", "Ya"},
+		{"ОЛЬГА Ю.", "OLGA Iu.", "OL`GA Yu."},
+		// украинские буквы и текст не на кириллице
+		{"Ґанна", "Ganna", "G`anna"},
+		{"Ivan Петров", "Ivan Petrov", "Ivan Petrov"},
+		{"O'Neil-Smith", "O'Neil-Smith", "O'Neil-Smith"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := Transliterate(tt.in, ICAO); got != tt.icao {
+			t.Errorf("Transliterate(%q, ICAO) = %q, want %q", tt.in, got, tt.icao)
+		}
+		if got := Transliterate(tt.in, GOST); got != tt.gost {
+			t.Errorf("Transliterate(%q, GOST) = %q, want %q", tt.in, got, tt.gost)
+		}
+	}
+}
+
+func TestTransliterateWithoutScheme(t *testing.T) {
+	if got := Transliterate("Иван", nil); got != "Иван" {
+		t.Errorf("Transliterate without scheme = %q, want the input", got)
+	}
+}
+
+func TestLatin(t *testing.T) {
+	if got := Latin("  Пётр \t Ильич ", Schemes["gost"]); got != "Pyotr Il`ich" {
+		t.Errorf("Latin = %q, want %q", got, "Pyotr Il`ich")
+	}
+}
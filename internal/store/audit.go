@@ -0,0 +1,22 @@
+package store
+
+import "context"
+
+// Audit identifies the author of changes recorded in the history of humans
+type Audit struct {
+	Actor     string
+	RequestID string
+}
+
+type auditKey struct{}
+
+// WithAudit returns a copy of ctx carrying a, which repositories attach to the changes they make
+func WithAudit(ctx context.Context, a Audit) context.Context {
+	return context.WithValue(ctx, auditKey{}, a)
+}
+
+// AuditFrom returns the audit of ctx, or an empty one
+func AuditFrom(ctx context.Context) Audit {
+	a, _ := ctx.Value(auditKey{}).(Audit)
+	return a
+}
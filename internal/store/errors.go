@@ -5,4 +5,7 @@ import "errors"
 var (
 	ErrHumanNotFound   = errors.New("human not found")
 	ErrNothingToUpdate = errors.New("nothing to update")
+	ErrCacheMiss       = errors.New("cache miss")
+	// ErrVersionConflict means the human was changed since the expected version was read
+	ErrVersionConflict = errors.New("version conflict")
 )
@@ -3,11 +3,68 @@ package store
 import (
 	"context"
 	"effectiveMobile/internal/model"
+	"time"
 )
 
 type HumanRepository interface {
+	// AddHuman inserts human and fills in its ID and the values set by the database
 	AddHuman(ctx context.Context, human *model.Human) error
+	// AddHumans inserts humans in a single transaction and fills them in like AddHuman
+	AddHumans(ctx context.Context, humans []model.Human) error
+	GetHuman(ctx context.Context, id int) (*model.Human, error)
 	GetHumans(ctx context.Context, f *model.HumanFilter) ([]model.Human, error)
-	UpdateHuman(ctx context.Context, human *model.Human) error
-	DeleteHuman(ctx context.Context, id int) error
+	// SearchHumans finds humans whose name, surname or patronymic is similar to q by trigrams,
+	// in any script, best matches first; only matches with similarity of at least threshold are returned
+	SearchHumans(ctx context.Context, q string, threshold float64, limit int) ([]model.SearchHit, error)
+	// FindDuplicates examines up to limit humans with ID greater than after and groups them with the humans
+	// whose search keys are similar by at least threshold into clusters, most similar first.
+	// next is the ID to pass as after for the following page, 0 when the table is exhausted.
+	FindDuplicates(ctx context.Context, threshold float64, after, limit int) (clusters []model.DuplicateCluster, next int, err error)
+	// MergeHumans fills empty fields of the survivor from the merged humans, records
+	// their snapshots in the merge history and deletes them, in a single transaction
+	MergeHumans(ctx context.Context, survivorID int, mergedIDs []int) error
+	// CountHumans counts humans matching f regardless of pagination. When more than exactLimit
+	// humans match, the planner estimate is returned and estimated is set; 0 always counts exactly.
+	CountHumans(ctx context.Context, f *model.HumanFilter, exactLimit int) (total int64, estimated bool, err error)
+	// StreamHumans calls fn for every human matching f regardless of pagination.
+	// The human passed to fn is reused between calls.
+	StreamHumans(ctx context.Context, f *model.HumanFilter, fn func(*model.Human) error) error
+	// UpdateHuman changes the fields set in patch. A positive version is the expected
+	// current version of the human; ErrVersionConflict is returned when it differs.
+	UpdateHuman(ctx context.Context, id, version int, patch *model.HumanPatch) error
+	// ReplaceHuman changes every field of a human and sets its new version.
+	// A positive human.Version is checked like in UpdateHuman.
+	ReplaceHuman(ctx context.Context, human *model.Human) error
+	// DeleteHuman marks a human as deleted; it is hidden from every other method
+	// except GetHumans with IncludeDeleted and can be restored until purged.
+	// A positive version is checked like in UpdateHuman.
+	DeleteHuman(ctx context.Context, id, version int) error
+	// RestoreHuman clears the deletion mark of a human. A positive version is the expected
+	// current version of the human; ErrVersionConflict is returned when it differs.
+	RestoreHuman(ctx context.Context, id, version int) error
+	// PurgeDeletedHumans removes up to limit humans deleted before the given time for good
+	// and returns their number
+	PurgeDeletedHumans(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+	// GetHistory returns the changes of a human, oldest first; they outlive the human itself
+	GetHistory(ctx context.Context, id int) ([]model.HistoryEntry, error)
+	GetHumanIDsByStatus(ctx context.Context, status string, limit int) ([]int, error)
+	// SaveEnrichment stores inferred attributes and the enrichment status of a human read at human.Version.
+	// ErrVersionConflict is returned when the human was changed or deleted since then.
+	SaveEnrichment(ctx context.Context, human *model.Human) error
+	// BackfillSearchKeys computes missing search keys of up to limit humans and returns their number
+	BackfillSearchKeys(ctx context.Context, limit int) (int, error)
+	// ResetEnrichment moves a human back to pending
+	ResetEnrichment(ctx context.Context, id int) error
+	// ResetEnrichments moves humans with the given status (enriched and failed when empty)
+	// and enriched before the given time (any time when zero) back to pending.
+	// A positive limit caps the number of humans; their IDs are returned, and more reports
+	// that matching humans are left for another call.
+	ResetEnrichments(ctx context.Context, status string, enrichedBefore time.Time, limit int) (ids []int, more bool, err error)
+}
+
+type EnrichmentCacheRepository interface {
+	GetEntry(ctx context.Context, provider, name string) (*model.EnrichmentCacheEntry, error)
+	PutEntry(ctx context.Context, entry *model.EnrichmentCacheEntry) error
+	// DeleteEntries removes entries of all providers for name, or every entry when name is empty
+	DeleteEntries(ctx context.Context, name string) (int64, error)
 }
@@ -0,0 +1,280 @@
+package sqlstore
+
+import (
+	"context"
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/store"
+	"github.com/jackc/pgx/v5"
+	"sort"
+	"strconv"
+)
+
+// duplicatePairsPerHuman ограничивает число похожих записей, читаемых для одной просмотренной записи
+const duplicatePairsPerHuman = 20
+
+func (h *HumanRepository) FindDuplicates(ctx context.Context, threshold float64, after, limit int) ([]model.DuplicateCluster, int, error) {
+	// Просматриваются limit записей с id больше after; для каждой по trigram-индексу читаются
+	// не более duplicatePairsPerHuman самых похожих записей с большим id, так что работа запроса
+	// ограничена независимо от размера таблицы. Пара находится со стороны меньшего id,
+	// поэтому корень каждого кластера лежит в просмотренном окне.
+	// Равные ключи поиска имеют похожесть 1, поэтому оператор % находит и точные совпадения.
+	const query = `
+        SELECT a.id, m.id, m.score
+          FROM (SELECT id, search_key
+                  FROM people
+                 WHERE id > $1 AND search_key <> '' AND deleted_at IS NULL
+                 ORDER BY id
+                 LIMIT $2) a
+          LEFT JOIN LATERAL (
+                SELECT b.id, similarity(a.search_key, b.search_key) AS score
+                  FROM people b
+                 WHERE b.search_key % a.search_key AND b.id > a.id AND b.deleted_at IS NULL
+                 ORDER BY score DESC, b.id
+                 LIMIT $3) m ON true
+         ORDER BY a.id, m.score DESC, m.id
+    `
+	type pair struct {
+		a, b  int
+		score float64
+	}
+	var (
+		pairs   []pair
+		scanned int
+		last    int
+	)
+	err := pgx.BeginFunc(ctx, h.store.db, func(tx pgx.Tx) error {
+		// Порог оператора % задаётся на транзакцию (как SET LOCAL): соединение идёт по trigram-индексу
+		// idx_people_search_key_trgm и ограничено парами не ниже порога
+		if _, err := tx.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1, true)`,
+			strconv.FormatFloat(threshold, 'f', -1, 64)); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, query, after, limit, duplicatePairsPerHuman)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				a     int
+				b     *int
+				score *float64
+			)
+			if err := rows.Scan(&a, &b, &score); err != nil {
+				return err
+			}
+			if a != last {
+				scanned++
+				last = a
+			}
+			// Запись без похожих приходит одной строкой с NULL
+			if b != nil {
+				pairs = append(pairs, pair{a: a, b: *b, score: *score})
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	// Окно заполнено целиком: следующая страница начинается после последней просмотренной записи
+	next := 0
+	if scanned == limit {
+		next = last
+	}
+
+	// Пары объединяются в кластеры через систему непересекающихся множеств
+	parent := make(map[int]int)
+	var find func(id int) int
+	find = func(id int) int {
+		p, ok := parent[id]
+		if !ok || p == id {
+			parent[id] = id
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+	for _, p := range pairs {
+		ra, rb := find(p.a), find(p.b)
+		if ra != rb {
+			parent[max(ra, rb)] = min(ra, rb)
+		}
+	}
+	members := make(map[int][]int)
+	scores := make(map[int]float64)
+	for id := range parent {
+		root := find(id)
+		members[root] = append(members[root], id)
+	}
+	for _, p := range pairs {
+		root := find(p.a)
+		scores[root] = max(scores[root], p.score)
+	}
+
+	roots := make([]int, 0, len(members))
+	for root := range members {
+		roots = append(roots, root)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		if scores[roots[i]] != scores[roots[j]] {
+			return scores[roots[i]] > scores[roots[j]]
+		}
+		return roots[i] < roots[j]
+	})
+	var ids []int
+	for _, root := range roots {
+		ids = append(ids, members[root]...)
+	}
+	humans, err := h.getHumansByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[int]model.Human, len(humans))
+	for _, human := range humans {
+		byID[human.Id] = human
+	}
+
+	clusters := make([]model.DuplicateCluster, 0, len(roots))
+	for _, root := range roots {
+		ids := members[root]
+		sort.Ints(ids)
+		c := model.DuplicateCluster{Score: scores[root]}
+		for _, id := range ids {
+			// Запись могла быть удалена между запросами
+			if human, ok := byID[id]; ok {
+				c.Humans = append(c.Humans, human)
+			}
+		}
+		if len(c.Humans) > 1 {
+			clusters = append(clusters, c)
+		}
+	}
+	return clusters, next, nil
+}
+
+// getHumansByIDs loads humans with their details; missing IDs are skipped
+func (h *HumanRepository) getHumansByIDs(ctx context.Context, ids []int) ([]model.Human, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := h.store.db.Query(ctx, `SELECT `+humanColumns+` FROM people WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var humans []model.Human
+	for rows.Next() {
+		var human model.Human
+		if err := scanHuman(rows, &human); err != nil {
+			return nil, err
+		}
+		humans = append(humans, human)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := h.attachDetails(ctx, humans); err != nil {
+		return nil, err
+	}
+	return humans, nil
+}
+
+func (h *HumanRepository) MergeHumans(ctx context.Context, survivorID int, mergedIDs []int) error {
+	ids := append([]int{survivorID}, mergedIDs...)
+	return h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `SELECT `+humanColumns+` FROM people WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id FOR UPDATE`, ids)
+		if err != nil {
+			return err
+		}
+		locked := make(map[int]model.Human, len(ids))
+		for rows.Next() {
+			var human model.Human
+			if err := scanHuman(rows, &human); err != nil {
+				rows.Close()
+				return err
+			}
+			locked[human.Id] = human
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(locked) != len(ids) {
+			return store.ErrHumanNotFound
+		}
+
+		// Снимок поглощаемых записей вместе с дочерними таблицами сохраняется до переноса данных
+		const history = `
+            INSERT INTO people_merges (survivor_id, merged_id, merged_record)
+            SELECT $1, p.id, to_jsonb(p) || jsonb_build_object(
+                       'nationalities', (SELECT COALESCE(jsonb_agg(to_jsonb(n) - 'human_id' ORDER BY n.rank), '[]')
+                                           FROM people_nationalities n WHERE n.human_id = p.id),
+                       'enrichment', (SELECT COALESCE(jsonb_agg(to_jsonb(e) - 'human_id' ORDER BY e.attribute), '[]')
+                                        FROM people_enrichment e WHERE e.human_id = p.id))
+              FROM people p
+             WHERE p.id = ANY($2)
+        `
+		if _, err := tx.Exec(ctx, history, survivorID, mergedIDs); err != nil {
+			return err
+		}
+
+		// Пустые поля выжившей записи заполняются из поглощаемых в порядке mergedIDs,
+		// вместе со сведениями о происхождении значения
+		survivor := locked[survivorID]
+		moves := make(map[string]int)
+		for _, id := range mergedIDs {
+			m := locked[id]
+			if survivor.Patronymic == "" && m.Patronymic != "" {
+				survivor.Patronymic = m.Patronymic
+			}
+			if survivor.Age == 0 && m.Age != 0 {
+				survivor.Age = m.Age
+				moves[model.AttributeAge] = id
+			}
+			if (survivor.Gender == "" || survivor.Gender == "unknown") && m.Gender != "" && m.Gender != "unknown" {
+				survivor.Gender = m.Gender
+				moves[model.AttributeGender] = id
+			}
+			if survivor.Nationality == "" && m.Nationality != "" {
+				survivor.Nationality = m.Nationality
+				moves[model.AttributeNationality] = id
+			}
+		}
+
+		const update = `
+            UPDATE people
+               SET patronymic = $2, age = $3, gender = $4, nationality = $5, version = version + 1
+             WHERE id = $1
+        `
+		if _, err := tx.Exec(ctx, update,
+			survivorID, survivor.Patronymic, survivor.Age, survivor.Gender, survivor.Nationality,
+		); err != nil {
+			return err
+		}
+		for attribute, from := range moves {
+			if err := dropEnrichment(ctx, tx, survivorID, []string{attribute}); err != nil {
+				return err
+			}
+			const move = `UPDATE people_enrichment SET human_id = $1 WHERE human_id = $2 AND attribute = $3`
+			if _, err := tx.Exec(ctx, move, survivorID, from, attribute); err != nil {
+				return err
+			}
+		}
+		if from, ok := moves[model.AttributeNationality]; ok {
+			if _, err := tx.Exec(ctx, `DELETE FROM people_nationalities WHERE human_id = $1`, survivorID); err != nil {
+				return err
+			}
+			const move = `UPDATE people_nationalities SET human_id = $1 WHERE human_id = $2`
+			if _, err := tx.Exec(ctx, move, survivorID, from); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM people WHERE id = ANY($1)`, mergedIDs); err != nil {
+			return err
+		}
+		return refreshSearchKey(ctx, tx, survivorID)
+	})
+}
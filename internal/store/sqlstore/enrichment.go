@@ -0,0 +1,151 @@
+package sqlstore
+
+import (
+	"context"
+	"effectiveMobile/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// saveEnrichment upserts the provenance of inferred attributes of a human
+func saveEnrichment(ctx context.Context, tx pgx.Tx, humanID int, enrichment []model.Enrichment) error {
+	if len(enrichment) == 0 {
+		return nil
+	}
+	const query = `
+        INSERT INTO people_enrichment
+            (human_id, attribute, value, provider, probability, sample_count, fetched_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (human_id, attribute) DO UPDATE SET
+            value = EXCLUDED.value,
+            provider = EXCLUDED.provider,
+            probability = EXCLUDED.probability,
+            sample_count = EXCLUDED.sample_count,
+            fetched_at = EXCLUDED.fetched_at
+    `
+	batch := &pgx.Batch{}
+	for _, e := range enrichment {
+		batch.Queue(query, humanID, e.Attribute, e.Value, e.Provider, e.Probability, e.SampleCount, e.FetchedAt)
+	}
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+// dropEnrichment removes the provenance of attributes that were set manually
+func dropEnrichment(ctx context.Context, tx pgx.Tx, humanID int, attributes []string) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	const query = `DELETE FROM people_enrichment WHERE human_id = $1 AND attribute = ANY($2)`
+	_, err := tx.Exec(ctx, query, humanID, attributes)
+	return err
+}
+
+// loadEnrichment returns the provenance of attributes grouped by human ID
+func (h *HumanRepository) loadEnrichment(ctx context.Context, ids []int) (map[int][]model.Enrichment, error) {
+	result := make(map[int][]model.Enrichment, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	const query = `
+        SELECT human_id, attribute, value, provider, probability, sample_count, fetched_at
+          FROM people_enrichment
+         WHERE human_id = ANY($1)
+         ORDER BY human_id, attribute
+    `
+	rows, err := h.store.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id int
+			e  model.Enrichment
+		)
+		if err := rows.Scan(&id, &e.Attribute, &e.Value, &e.Provider, &e.Probability, &e.SampleCount, &e.FetchedAt); err != nil {
+			return nil, err
+		}
+		result[id] = append(result[id], e)
+	}
+	return result, rows.Err()
+}
+
+// saveNationalities replaces the ranked candidate countries of a human
+func saveNationalities(ctx context.Context, tx pgx.Tx, humanID int, nationalities []model.Nationality) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM people_nationalities WHERE human_id = $1`, humanID); err != nil {
+		return err
+	}
+	if len(nationalities) == 0 {
+		return nil
+	}
+	rows := make([][]any, 0, len(nationalities))
+	for i, n := range nationalities {
+		rows = append(rows, []any{humanID, n.CountryID, n.Probability, i + 1})
+	}
+	_, err := tx.CopyFrom(ctx,
+		pgx.Identifier{"people_nationalities"},
+		[]string{"human_id", "country_id", "probability", "rank"},
+		pgx.CopyFromRows(rows),
+	)
+	return err
+}
+
+// manualNationality is the candidate list of a nationality set by hand
+func manualNationality(nationality string) []model.Nationality {
+	if nationality == "" {
+		return nil
+	}
+	return []model.Nationality{{CountryID: nationality, Probability: 1}}
+}
+
+// loadNationalities returns the ranked candidate countries grouped by human ID
+func (h *HumanRepository) loadNationalities(ctx context.Context, ids []int) (map[int][]model.Nationality, error) {
+	result := make(map[int][]model.Nationality, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	const query = `
+        SELECT human_id, country_id, probability
+          FROM people_nationalities
+         WHERE human_id = ANY($1)
+         ORDER BY human_id, rank
+    `
+	rows, err := h.store.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id int
+			n  model.Nationality
+		)
+		if err := rows.Scan(&id, &n.CountryID, &n.Probability); err != nil {
+			return nil, err
+		}
+		result[id] = append(result[id], n)
+	}
+	return result, rows.Err()
+}
+
+// attachDetails loads the child records of humans
+func (h *HumanRepository) attachDetails(ctx context.Context, humans []model.Human) error {
+	ids := make([]int, 0, len(humans))
+	for _, human := range humans {
+		ids = append(ids, human.Id)
+	}
+	enrichment, err := h.loadEnrichment(ctx, ids)
+	if err != nil {
+		return err
+	}
+	nationalities, err := h.loadNationalities(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range humans {
+		humans[i].Enrichment = enrichment[humans[i].Id]
+		humans[i].Nationalities = nationalities[humans[i].Id]
+	}
+	return nil
+}
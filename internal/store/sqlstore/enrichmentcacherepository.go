@@ -0,0 +1,61 @@
+package sqlstore
+
+import (
+	"context"
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/store"
+	"errors"
+	"github.com/jackc/pgx/v5"
+)
+
+type EnrichmentCacheRepository struct {
+	store *Store
+}
+
+func (c *EnrichmentCacheRepository) GetEntry(ctx context.Context, provider, name string) (*model.EnrichmentCacheEntry, error) {
+	const query = `
+        SELECT provider, name, result, fetched_at
+          FROM name_enrichment_cache
+         WHERE provider = $1 AND name = $2
+    `
+	var entry model.EnrichmentCacheEntry
+	err := c.store.db.QueryRow(ctx, query, provider, name).Scan(
+		&entry.Provider,
+		&entry.Name,
+		&entry.Result,
+		&entry.FetchedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, store.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (c *EnrichmentCacheRepository) PutEntry(ctx context.Context, entry *model.EnrichmentCacheEntry) error {
+	const query = `
+        INSERT INTO name_enrichment_cache (provider, name, result, fetched_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (provider, name)
+        DO UPDATE SET result = EXCLUDED.result, fetched_at = EXCLUDED.fetched_at
+    `
+	_, err := c.store.db.Exec(ctx, query, entry.Provider, entry.Name, entry.Result, entry.FetchedAt)
+	return err
+}
+
+func (c *EnrichmentCacheRepository) DeleteEntries(ctx context.Context, name string) (int64, error) {
+	if name == "" {
+		tag, err := c.store.db.Exec(ctx, `DELETE FROM name_enrichment_cache`)
+		if err != nil {
+			return 0, err
+		}
+		return tag.RowsAffected(), nil
+	}
+	tag, err := c.store.db.Exec(ctx, `DELETE FROM name_enrichment_cache WHERE name = $1`, name)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
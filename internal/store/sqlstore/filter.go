@@ -0,0 +1,59 @@
+package sqlstore
+
+import (
+	"effectiveMobile/internal/filter"
+	"effectiveMobile/internal/model"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"strings"
+)
+
+// compileFilter renders a parsed filter expression as a parameterized condition.
+// Field names are checked by the parser against model.FilterFields; values always go to args.
+func compileFilter(e filter.Expr, args []interface{}) (string, []interface{}) {
+	switch e := e.(type) {
+	case *filter.Logical:
+		var left, right string
+		left, args = compileFilter(e.Left, args)
+		right, args = compileFilter(e.Right, args)
+		return "(" + left + " " + strings.ToUpper(e.Op) + " " + right + ")", args
+	case *filter.Not:
+		var x string
+		x, args = compileFilter(e.X, args)
+		return "NOT (" + x + ")", args
+	case *filter.Compare:
+		op := e.Op
+		switch op {
+		case "!=":
+			op = "<>"
+		case "like":
+			op = "ILIKE"
+		}
+		args = append(args, e.Value.Any())
+		return fmt.Sprintf("%s %s $%d", pgx.Identifier{e.Field}.Sanitize(), op, len(args)), args
+	case *filter.In:
+		placeholders := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			args = append(args, v.Any())
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		op := "IN"
+		if e.Not {
+			op = "NOT IN"
+		}
+		return fmt.Sprintf("%s %s (%s)", pgx.Identifier{e.Field}.Sanitize(), op, strings.Join(placeholders, ", ")), args
+	case *filter.IsNull:
+		col := pgx.Identifier{e.Field}.Sanitize()
+		// Отсутствующий текст хранится пустой строкой; поля со списком значений — enum-колонки,
+		// в которых пустой строки не бывает, и '' для них недопустимое значение
+		if f := model.FilterFields[e.Field]; f.Type == filter.String && len(f.Values) == 0 {
+			col = "NULLIF(" + col + ", '')"
+		}
+		if e.Not {
+			return col + " IS NOT NULL", args
+		}
+		return col + " IS NULL", args
+	default:
+		panic(fmt.Sprintf("sqlstore: unexpected filter node %T", e))
+	}
+}
@@ -0,0 +1,42 @@
+package sqlstore
+
+import (
+	"effectiveMobile/internal/filter"
+	"effectiveMobile/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestCompileFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+		args []interface{}
+	}{
+		{"age >= 18 and name like 'Iv%'", `("age" >= $1 AND "name" ILIKE $2)`, []interface{}{18, "Iv%"}},
+		{"not gender != male", `NOT ("gender" <> $1)`, []interface{}{"male"}},
+		{"nationality not in ('RU', 'KZ')", `"nationality" NOT IN ($1, $2)`, []interface{}{"RU", "KZ"}},
+
+		// пустой текст считается отсутствующим, а enum-колонки и числа сравниваются с NULL как есть
+		{"patronymic is null", `NULLIF("patronymic", '') IS NULL`, nil},
+		{"nationality is not null", `NULLIF("nationality", '') IS NOT NULL`, nil},
+		{"gender is null", `"gender" IS NULL`, nil},
+		{"enrichment_status is not null", `"enrichment_status" IS NOT NULL`, nil},
+		{"age is null", `"age" IS NULL`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := filter.Parse(tt.expr, model.FilterFields)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+			}
+			got, args := compileFilter(e, nil)
+			if got != tt.want {
+				t.Errorf("compileFilter = %s, want %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package sqlstore
+
+import (
+	"context"
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+func (h *HumanRepository) GetHistory(ctx context.Context, id int) ([]model.HistoryEntry, error) {
+	const query = `
+        SELECT id, human_id, action, old_values, new_values, actor, request_id, changed_at
+          FROM people_history
+         WHERE human_id = $1
+         ORDER BY id
+    `
+	rows, err := h.store.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.HistoryEntry, error) {
+		var e model.HistoryEntry
+		err := row.Scan(&e.ID, &e.HumanID, &e.Action, &e.OldValues, &e.NewValues, &e.Actor, &e.RequestID, &e.ChangedAt)
+		return e, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return entries, nil
+	}
+
+	// Записи, созданные до появления журнала, существуют без истории
+	var exists bool
+	if err := h.store.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM people WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, store.ErrHumanNotFound
+	}
+	return entries, nil
+}
@@ -3,116 +3,397 @@ package sqlstore
 import (
 	"context"
 	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/normalize"
 	"effectiveMobile/internal/store"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/jackc/pgx/v5"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type HumanRepository struct {
 	store *Store
 }
 
+// humanColumns is the select list matching scanHuman
+const humanColumns = `
+            id, name, surname, patronymic,
+            age, gender, nationality,
+            enrichment_status, COALESCE(enrichment_error, ''), enriched_at,
+            deleted_at, version, created_at, updated_at`
+
+// scanHuman scans humanColumns into human, followed by any extra columns
+func scanHuman(row pgx.Row, human *model.Human, extra ...interface{}) error {
+	dest := []interface{}{
+		&human.Id,
+		&human.Name,
+		&human.Surname,
+		&human.Patronymic,
+		&human.Age,
+		&human.Gender,
+		&human.Nationality,
+		&human.EnrichmentStatus,
+		&human.EnrichmentError,
+		&human.EnrichedAt,
+		&human.DeletedAt,
+		&human.Version,
+		&human.CreatedAt,
+		&human.UpdatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+const insertHumanQuery = `INSERT INTO people (name, surname, patronymic, age, gender, nationality, enrichment_status, search_key) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING ` + humanColumns
+
+func insertHumanArgs(human *model.Human) []any {
+	if human.EnrichmentStatus == "" {
+		human.EnrichmentStatus = model.EnrichmentPending
+	}
+	return []any{
+		human.Name, human.Surname, human.Patronymic, human.Age, human.Gender, human.Nationality, human.EnrichmentStatus,
+		normalize.SearchKey(human.Name, human.Surname, human.Patronymic),
+	}
+}
+
+// saveDetails writes the child records of a freshly inserted human
+func saveDetails(ctx context.Context, tx pgx.Tx, human *model.Human) error {
+	if err := saveEnrichment(ctx, tx, human.Id, human.Enrichment); err != nil {
+		return err
+	}
+	nationalities := human.Nationalities
+	if len(nationalities) == 0 {
+		nationalities = manualNationality(human.Nationality)
+	}
+	if len(nationalities) == 0 {
+		return nil
+	}
+	return saveNationalities(ctx, tx, human.Id, nationalities)
+}
+
 func (h *HumanRepository) AddHuman(ctx context.Context, human *model.Human) error {
-	const query = `INSERT INTO people (name, surname, patronymic, age, gender, nationality) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := h.store.db.Exec(ctx, query, human.Name, human.Surname, human.Patronymic, human.Age, human.Gender, human.Nationality)
+	return h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := scanHuman(tx.QueryRow(ctx, insertHumanQuery, insertHumanArgs(human)...), human); err != nil {
+			return err
+		}
+		return saveDetails(ctx, tx, human)
+	})
+}
+
+func (h *HumanRepository) AddHumans(ctx context.Context, humans []model.Human) error {
+	if len(humans) == 0 {
+		return nil
+	}
+	return h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for i := range humans {
+			batch.Queue(insertHumanQuery, insertHumanArgs(&humans[i])...)
+		}
+		results := tx.SendBatch(ctx, batch)
+		for i := range humans {
+			if err := scanHuman(results.QueryRow(), &humans[i]); err != nil {
+				results.Close()
+				return err
+			}
+		}
+		if err := results.Close(); err != nil {
+			return err
+		}
+		for i := range humans {
+			if err := saveDetails(ctx, tx, &humans[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (h *HumanRepository) GetHuman(ctx context.Context, id int) (*model.Human, error) {
+	query := `SELECT ` + humanColumns + ` FROM people WHERE id = $1 AND deleted_at IS NULL`
+	var human model.Human
+	err := scanHuman(h.store.db.QueryRow(ctx, query, id), &human)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, store.ErrHumanNotFound
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	humans := []model.Human{human}
+	if err := h.attachDetails(ctx, humans); err != nil {
+		return nil, err
+	}
+	return &humans[0], nil
 }
 
-func (h *HumanRepository) DeleteHuman(ctx context.Context, id int) error {
+func (h *HumanRepository) DeleteHuman(ctx context.Context, id, version int) error {
 	const query = `
-        DELETE FROM people
-         WHERE id = $1
+        UPDATE people
+           SET deleted_at = now(), version = version + 1
+         WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)
     `
-	tag, err := h.store.db.Exec(ctx, query, id)
+	return h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, id, version)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return missingOrConflict(ctx, tx, id)
+		}
+		return nil
+	})
+}
+
+// missingOrConflict tells why a conditional update of a human matched no rows
+func missingOrConflict(ctx context.Context, tx pgx.Tx, id int) error {
+	var exists bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM people WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists)
 	if err != nil {
 		return err
 	}
-	if tag.RowsAffected() == 0 {
-		return store.ErrHumanNotFound
+	if exists {
+		return store.ErrVersionConflict
 	}
-	return nil
+	return store.ErrHumanNotFound
 }
 
-func (h *HumanRepository) UpdateHuman(ctx context.Context, human *model.Human) error {
+func (h *HumanRepository) RestoreHuman(ctx context.Context, id, version int) error {
+	// Восстановление не удалённой записи ничего не меняет и в историю не попадает
+	const query = `
+        UPDATE people
+           SET deleted_at = NULL, version = version + (deleted_at IS NOT NULL)::int
+         WHERE id = $1 AND ($2 = 0 OR version = $2)
+    `
+	return h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, id, version)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() > 0 {
+			return nil
+		}
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM people WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return store.ErrVersionConflict
+		}
+		return store.ErrHumanNotFound
+	})
+}
+
+func (h *HumanRepository) PurgeDeletedHumans(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	const query = `
+        DELETE FROM people
+         WHERE id IN (
+               SELECT id
+                 FROM people
+                WHERE deleted_at < $1
+                ORDER BY deleted_at
+                LIMIT $2
+                  FOR UPDATE SKIP LOCKED
+         )
+    `
+	var purged int64
+	err := h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, deletedBefore, limit)
+		if err != nil {
+			return err
+		}
+		purged = tag.RowsAffected()
+		return nil
+	})
+	return purged, err
+}
+
+func (h *HumanRepository) UpdateHuman(ctx context.Context, id, version int, patch *model.HumanPatch) error {
 	var (
-		setParts []string
-		args     []interface{}
+		setParts   []string
+		args       []interface{}
+		attributes []string
 	)
 
-	if human.Name != "" {
-		args = append(args, human.Name)
+	if patch.Name != nil {
+		args = append(args, *patch.Name)
 		setParts = append(setParts, fmt.Sprintf("name = $%d", len(args)))
 	}
-	if human.Surname != "" {
-		args = append(args, human.Surname)
+	if patch.Surname != nil {
+		args = append(args, *patch.Surname)
 		setParts = append(setParts, fmt.Sprintf("surname = $%d", len(args)))
 	}
-	if human.Patronymic != "" {
-		args = append(args, human.Patronymic)
+	if patch.Patronymic != nil {
+		args = append(args, *patch.Patronymic)
 		setParts = append(setParts, fmt.Sprintf("patronymic = $%d", len(args)))
 	}
-	if human.Age > 0 {
-		args = append(args, human.Age)
+	if patch.Age != nil {
+		args = append(args, *patch.Age)
 		setParts = append(setParts, fmt.Sprintf("age = $%d", len(args)))
+		attributes = append(attributes, model.AttributeAge)
 	}
-	if human.Gender != "" {
-		args = append(args, human.Gender)
+	if patch.Gender != nil {
+		args = append(args, *patch.Gender)
 		setParts = append(setParts, fmt.Sprintf("gender = $%d", len(args)))
+		attributes = append(attributes, model.AttributeGender)
 	}
-	if human.Nationality != "" {
-		args = append(args, human.Nationality)
+	if patch.Nationality != nil {
+		args = append(args, *patch.Nationality)
 		setParts = append(setParts, fmt.Sprintf("nationality = $%d", len(args)))
+		attributes = append(attributes, model.AttributeNationality)
 	}
 
 	if len(setParts) == 0 {
 		return store.ErrNothingToUpdate
 	}
 
-	args = append(args, human.Id)
+	setParts = append(setParts, "version = version + 1")
+
+	args = append(args, id)
 	idPosition := len(args)
 	query := fmt.Sprintf(
-		"UPDATE people SET %s WHERE id = $%d",
+		"UPDATE people SET %s WHERE id = $%d AND deleted_at IS NULL",
 		strings.Join(setParts, ", "),
 		idPosition,
 	)
+	if version > 0 {
+		args = append(args, version)
+		query += fmt.Sprintf(" AND version = $%d", len(args))
+	}
 
 	// Выполняем запрос
-	tag, err := h.store.db.Exec(ctx, query, args...)
+	return h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return missingOrConflict(ctx, tx, id)
+		}
+		if patch.Name != nil || patch.Surname != nil || patch.Patronymic != nil {
+			if err := refreshSearchKey(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		if patch.Nationality != nil {
+			if err := saveNationalities(ctx, tx, id, manualNationality(*patch.Nationality)); err != nil {
+				return err
+			}
+		}
+		return dropEnrichment(ctx, tx, id, attributes)
+	})
+}
+
+func (h *HumanRepository) ReplaceHuman(ctx context.Context, human *model.Human) error {
+	const query = `
+        UPDATE people
+           SET name = $1, surname = $2, patronymic = $3,
+               age = $4, gender = $5, nationality = $6,
+               search_key = $8, version = version + 1
+         WHERE id = $7 AND deleted_at IS NULL AND ($9 = 0 OR version = $9)
+        RETURNING version
+    `
+	return h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query,
+			human.Name, human.Surname, human.Patronymic,
+			human.Age, human.Gender, human.Nationality,
+			human.Id,
+			normalize.SearchKey(human.Name, human.Surname, human.Patronymic),
+			human.Version,
+		).Scan(&human.Version)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return missingOrConflict(ctx, tx, human.Id)
+		}
+		if err != nil {
+			return err
+		}
+		if err := saveNationalities(ctx, tx, human.Id, manualNationality(human.Nationality)); err != nil {
+			return err
+		}
+		// Значения заданы вручную, поэтому сведения об их происхождении больше не верны
+		return dropEnrichment(ctx, tx, human.Id, []string{
+			model.AttributeAge, model.AttributeGender, model.AttributeNationality,
+		})
+	})
+}
+
+// refreshSearchKey recomputes the search key after a partial update of the name
+func refreshSearchKey(ctx context.Context, tx pgx.Tx, id int) error {
+	var name, surname, patronymic string
+	err := tx.QueryRow(ctx,
+		`SELECT name, surname, COALESCE(patronymic, '') FROM people WHERE id = $1`, id,
+	).Scan(&name, &surname, &patronymic)
 	if err != nil {
 		return err
 	}
-	if tag.RowsAffected() == 0 {
-		return store.ErrHumanNotFound
+	_, err = tx.Exec(ctx, `UPDATE people SET search_key = $1 WHERE id = $2`,
+		normalize.SearchKey(name, surname, patronymic), id)
+	return err
+}
+
+func (h *HumanRepository) BackfillSearchKeys(ctx context.Context, limit int) (int, error) {
+	const query = `
+        SELECT id, COALESCE(name, ''), COALESCE(surname, ''), COALESCE(patronymic, '')
+          FROM people
+         WHERE search_key IS NULL
+         ORDER BY id
+         LIMIT $1
+    `
+	rows, err := h.store.db.Query(ctx, query, limit)
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	batch := &pgx.Batch{}
+	for rows.Next() {
+		var (
+			id                        int
+			name, surname, patronymic string
+		)
+		if err := rows.Scan(&id, &name, &surname, &patronymic); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch.Queue(`UPDATE people SET search_key = $1 WHERE id = $2`,
+			normalize.SearchKey(name, surname, patronymic), id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if batch.Len() == 0 {
+		return 0, nil
+	}
+	if err := h.store.db.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, err
+	}
+	return batch.Len(), nil
 }
 
-func (h *HumanRepository) GetHumans(ctx context.Context, f *model.HumanFilter) ([]model.Human, error) {
-	var sb strings.Builder
-	sb.WriteString(`
-        SELECT
-            id, name, surname, patronymic,
-            age, gender, nationality
-        FROM people
-    `)
+// likeEscaper escapes the wildcards of LIKE in a literal value
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
 
+// humanFilterWhere builds the WHERE clause of f starting with a space, or an empty string
+func humanFilterWhere(f *model.HumanFilter) (string, []interface{}) {
 	var whereClauses []string
 	var args []interface{}
 
-	if f.Name != "" {
-		args = append(args, "%"+f.Name+"%")
-		whereClauses = append(whereClauses, fmt.Sprintf("name ILIKE $%d", len(args)))
-	}
-	if f.Surname != "" {
-		args = append(args, "%"+f.Surname+"%")
-		whereClauses = append(whereClauses, fmt.Sprintf("surname ILIKE $%d", len(args)))
-	}
-	if f.Patronymic != "" {
-		args = append(args, "%"+f.Patronymic+"%")
-		whereClauses = append(whereClauses, fmt.Sprintf("patronymic ILIKE $%d", len(args)))
+	for _, part := range []struct{ column, value string }{
+		{"name", f.Name},
+		{"surname", f.Surname},
+		{"patronymic", f.Patronymic},
+	} {
+		if part.value == "" {
+			continue
+		}
+		args = append(args, "%"+part.value+"%")
+		cond := fmt.Sprintf("%s ILIKE $%d", part.column, len(args))
+		// Ключ поиска сравнивается с ключом значения, чтобы Dmitriy находил и «Дмитрий».
+		// Ключ строится по всему ФИО, поэтому совпадение в нём не привязано к полю
+		if key := normalize.SearchKey(part.value); key != "" {
+			args = append(args, "%"+likeEscaper.Replace(key)+"%")
+			cond = fmt.Sprintf("(%s OR search_key LIKE $%d)", cond, len(args))
+		}
+		whereClauses = append(whereClauses, cond)
 	}
 	if f.MinAge > 0 {
 		args = append(args, f.MinAge)
@@ -130,14 +411,74 @@ func (h *HumanRepository) GetHumans(ctx context.Context, f *model.HumanFilter) (
 		args = append(args, f.Nationality)
 		whereClauses = append(whereClauses, fmt.Sprintf("nationality = $%d", len(args)))
 	}
+	if len(f.NationalityAny) > 0 {
+		args = append(args, f.NationalityAny, f.NationalityMinProbability)
+		whereClauses = append(whereClauses, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM people_nationalities pn WHERE pn.human_id = people.id AND pn.country_id = ANY($%d) AND pn.probability >= $%d)",
+			len(args)-1, len(args),
+		))
+	}
+	if f.EnrichmentStatus != "" {
+		args = append(args, f.EnrichmentStatus)
+		whereClauses = append(whereClauses, fmt.Sprintf("enrichment_status = $%d", len(args)))
+	}
 	if f.ID > 0 {
 		args = append(args, f.ID)
 		whereClauses = append(whereClauses, fmt.Sprintf("id = $%d", len(args)))
 	}
+	if !f.IncludeDeleted {
+		whereClauses = append(whereClauses, "deleted_at IS NULL")
+	}
+	for _, bound := range []struct {
+		cond string
+		t    time.Time
+	}{
+		{"created_at > $%d", f.CreatedAfter},
+		{"created_at < $%d", f.CreatedBefore},
+		{"updated_at >= $%d", f.UpdatedSince},
+		{"updated_at < $%d", f.UpdatedBefore},
+	} {
+		if !bound.t.IsZero() {
+			args = append(args, bound.t)
+			whereClauses = append(whereClauses, fmt.Sprintf(bound.cond, len(args)))
+		}
+	}
+	if f.Expr != nil {
+		var cond string
+		cond, args = compileFilter(f.Expr, args)
+		whereClauses = append(whereClauses, cond)
+	}
 
-	if len(whereClauses) > 0 {
-		sb.WriteString(" WHERE ")
-		sb.WriteString(strings.Join(whereClauses, " AND "))
+	if len(whereClauses) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(whereClauses, " AND "), args
+}
+
+func (h *HumanRepository) GetHumans(ctx context.Context, f *model.HumanFilter) ([]model.Human, error) {
+	var sb strings.Builder
+	sb.WriteString(`SELECT ` + humanColumns + ` FROM people`)
+
+	where, args := humanFilterWhere(f)
+	sb.WriteString(where)
+
+	if f.After != nil {
+		var cond string
+		var err error
+		cond, args, err = keysetCondition(f.Sort, f.After, args)
+		if err != nil {
+			return nil, err
+		}
+		if where == "" {
+			sb.WriteString(" WHERE ")
+		} else {
+			sb.WriteString(" AND ")
+		}
+		sb.WriteString(cond)
+	}
+	if len(f.Sort) > 0 {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(orderBy(f.Sort))
 	}
 
 	if f.Page < 1 {
@@ -147,6 +488,9 @@ func (h *HumanRepository) GetHumans(ctx context.Context, f *model.HumanFilter) (
 		f.PageSize = 20
 	}
 	offset := (f.Page - 1) * f.PageSize
+	if f.After != nil {
+		offset = 0
+	}
 
 	args = append(args, f.PageSize, offset)
 	sb.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args)))
@@ -162,15 +506,7 @@ func (h *HumanRepository) GetHumans(ctx context.Context, f *model.HumanFilter) (
 	var humans []model.Human
 	for rows.Next() {
 		var h model.Human
-		if err := rows.Scan(
-			&h.Id,
-			&h.Name,
-			&h.Surname,
-			&h.Patronymic,
-			&h.Age,
-			&h.Gender,
-			&h.Nationality,
-		); err != nil {
+		if err := scanHuman(rows, &h); err != nil {
 			return nil, err
 		}
 		humans = append(humans, h)
@@ -179,5 +515,312 @@ func (h *HumanRepository) GetHumans(ctx context.Context, f *model.HumanFilter) (
 		return nil, rows.Err()
 	}
 
+	if err := h.attachDetails(ctx, humans); err != nil {
+		return nil, err
+	}
+
 	return humans, nil
 }
+
+func (h *HumanRepository) SearchHumans(ctx context.Context, q string, threshold float64, limit int) ([]model.SearchHit, error) {
+	// Операторы % и <% используют GIN-индексы по триграммам, similarity() считает оценку для сортировки.
+	// search_key сравнивается с ключом запроса, чтобы написания кириллицей и латиницей совпадали
+	query := `
+        SELECT ` + humanColumns + `,
+               GREATEST(
+                   similarity(name, $1), similarity(surname, $1), similarity(patronymic, $1),
+                   word_similarity($3, search_key)
+               ) AS score
+          FROM people
+         WHERE (name % $1 OR surname % $1 OR patronymic % $1 OR $3 <% search_key)
+           AND deleted_at IS NULL
+         ORDER BY score DESC, id
+         LIMIT $2
+    `
+	var hits []model.SearchHit
+	err := pgx.BeginFunc(ctx, h.store.db, func(tx pgx.Tx) error {
+		// Порог оператора % действует только внутри этой транзакции
+		const thresholds = `
+            SELECT set_config('pg_trgm.similarity_threshold', $1, true),
+                   set_config('pg_trgm.word_similarity_threshold', $1, true)
+        `
+		if _, err := tx.Exec(ctx, thresholds, strconv.FormatFloat(threshold, 'f', -1, 64)); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, query, q, limit, normalize.SearchKey(q))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var hit model.SearchHit
+			if err := scanHuman(rows, &hit.Human, &hit.Score); err != nil {
+				return err
+			}
+			hits = append(hits, hit)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	humans := make([]model.Human, len(hits))
+	for i := range hits {
+		humans[i] = hits[i].Human
+	}
+	if err := h.attachDetails(ctx, humans); err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		hits[i].Human = humans[i]
+	}
+	return hits, nil
+}
+
+func (h *HumanRepository) CountHumans(ctx context.Context, f *model.HumanFilter, exactLimit int) (int64, bool, error) {
+	where, args := humanFilterWhere(f)
+	if exactLimit <= 0 {
+		var total int64
+		err := h.store.db.QueryRow(ctx, `SELECT count(*) FROM people`+where, args...).Scan(&total)
+		return total, false, err
+	}
+
+	// Точный подсчёт ограничен exactLimit+1 строками, чтобы не сканировать всю таблицу
+	var total int64
+	query := fmt.Sprintf(`SELECT count(*) FROM (SELECT 1 FROM people%s LIMIT %d) AS matched`, where, exactLimit+1)
+	if err := h.store.db.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, false, err
+	}
+	if total <= int64(exactLimit) {
+		return total, false, nil
+	}
+
+	var plan []byte
+	if err := h.store.db.QueryRow(ctx, `EXPLAIN (FORMAT JSON) SELECT 1 FROM people`+where, args...).Scan(&plan); err != nil {
+		return 0, false, err
+	}
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explain); err != nil {
+		return 0, false, fmt.Errorf("parse query plan: %w", err)
+	}
+	if len(explain) == 0 {
+		return 0, false, errors.New("empty query plan")
+	}
+	if estimate := int64(explain[0].Plan.Rows); estimate > total {
+		total = estimate
+	}
+	return total, true, nil
+}
+
+// orderBy renders sort as the list of an ORDER BY clause
+func orderBy(sort []model.SortField) string {
+	parts := make([]string, len(sort))
+	for i, f := range sort {
+		parts[i] = pgx.Identifier{f.Column}.Sanitize()
+		if f.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keysetCondition matches humans placed after the cursor in the given order:
+// (a > $1) OR (a = $1 AND b < $2) OR ...
+func keysetCondition(sort []model.SortField, c *model.Cursor, args []interface{}) (string, []interface{}, error) {
+	if len(c.Values) != len(sort) {
+		return "", nil, model.ErrInvalidCursor
+	}
+	values := make([]interface{}, len(sort))
+	for i, f := range sort {
+		values[i] = c.Values[i]
+		switch f.Column {
+		case "id", "age":
+			v, err := strconv.Atoi(c.Values[i])
+			if err != nil {
+				return "", nil, model.ErrInvalidCursor
+			}
+			values[i] = v
+		case "created_at", "updated_at":
+			v, err := time.Parse(time.RFC3339Nano, c.Values[i])
+			if err != nil {
+				return "", nil, model.ErrInvalidCursor
+			}
+			values[i] = v
+		}
+	}
+
+	var or []string
+	for i := range sort {
+		var and []string
+		for j := 0; j <= i; j++ {
+			op := "="
+			if j == i {
+				op = ">"
+				if sort[j].Desc {
+					op = "<"
+				}
+			}
+			args = append(args, values[j])
+			and = append(and, fmt.Sprintf("%s %s $%d", pgx.Identifier{sort[j].Column}.Sanitize(), op, len(args)))
+		}
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	return "(" + strings.Join(or, " OR ") + ")", args, nil
+}
+
+// StreamHumans passes every human matching f to fn in id order, ignoring pagination.
+// Rows are read from the connection as fn consumes them, so memory stays constant.
+func (h *HumanRepository) StreamHumans(ctx context.Context, f *model.HumanFilter, fn func(*model.Human) error) error {
+	where, args := humanFilterWhere(f)
+	query := `SELECT ` + humanColumns + ` FROM people` + where + ` ORDER BY id`
+
+	rows, err := h.store.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var human model.Human
+	for rows.Next() {
+		human = model.Human{}
+		if err := scanHuman(rows, &human); err != nil {
+			return err
+		}
+		if err := fn(&human); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (h *HumanRepository) GetHumanIDsByStatus(ctx context.Context, status string, limit int) ([]int, error) {
+	const query = `
+        SELECT id
+          FROM people
+         WHERE enrichment_status = $1 AND deleted_at IS NULL
+         ORDER BY id
+         LIMIT $2
+    `
+	rows, err := h.store.db.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+func (h *HumanRepository) SaveEnrichment(ctx context.Context, human *model.Human) error {
+	const query = `
+        UPDATE people
+           SET age = $1, gender = $2, nationality = $3,
+               enrichment_status = $4, enrichment_error = NULLIF($5, ''), enriched_at = now(),
+               version = version + 1
+         WHERE id = $6 AND version = $7 AND deleted_at IS NULL
+    `
+	return h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query,
+			human.Age, human.Gender, human.Nationality,
+			human.EnrichmentStatus, human.EnrichmentError,
+			human.Id, human.Version,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			// Запись изменили или удалили, пока шло обогащение
+			return store.ErrVersionConflict
+		}
+		if err := saveEnrichment(ctx, tx, human.Id, human.Enrichment); err != nil {
+			return err
+		}
+		if len(human.Nationalities) == 0 && human.Nationality == "" {
+			return nil
+		}
+		nationalities := human.Nationalities
+		if len(nationalities) == 0 {
+			nationalities = manualNationality(human.Nationality)
+		}
+		return saveNationalities(ctx, tx, human.Id, nationalities)
+	})
+}
+
+func (h *HumanRepository) ResetEnrichment(ctx context.Context, id int) error {
+	const query = `UPDATE people SET enrichment_status = 'pending', version = version + 1 WHERE id = $1 AND deleted_at IS NULL`
+	return h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return store.ErrHumanNotFound
+		}
+		return nil
+	})
+}
+
+func (h *HumanRepository) ResetEnrichments(ctx context.Context, status string, enrichedBefore time.Time, limit int) ([]int, bool, error) {
+	var (
+		whereClauses []string
+		args         []interface{}
+	)
+	if status != "" {
+		args = append(args, status)
+		whereClauses = append(whereClauses, fmt.Sprintf("enrichment_status = $%d", len(args)))
+	} else {
+		whereClauses = append(whereClauses, "enrichment_status IN ('enriched', 'failed')")
+	}
+	whereClauses = append(whereClauses, "deleted_at IS NULL")
+	if !enrichedBefore.IsZero() {
+		// Записи, обогащённые до появления enriched_at, считаются устаревшими
+		args = append(args, enrichedBefore)
+		whereClauses = append(whereClauses, fmt.Sprintf("COALESCE(enriched_at, '-infinity') < $%d", len(args)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`SELECT id FROM people WHERE `)
+	sb.WriteString(strings.Join(whereClauses, " AND "))
+	sb.WriteString(` ORDER BY enriched_at NULLS FIRST, id`)
+	if limit > 0 {
+		// Лишняя строка показывает, что подходящие записи остались
+		args = append(args, limit+1)
+		sb.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+	}
+	sb.WriteString(` FOR UPDATE`)
+
+	const update = `
+        UPDATE people SET enrichment_status = 'pending', version = version + 1
+         WHERE id = ANY($1)
+        RETURNING id
+    `
+	var (
+		ids  []int
+		more bool
+	)
+	err := h.store.beginFunc(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sb.String(), args...)
+		if err != nil {
+			return err
+		}
+		selected, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return err
+		}
+		if limit > 0 && len(selected) > limit {
+			selected, more = selected[:limit], true
+		}
+		if len(selected) == 0 {
+			return nil
+		}
+		rows, err = tx.Query(ctx, update, selected)
+		if err != nil {
+			return err
+		}
+		ids, err = pgx.CollectRows(rows, pgx.RowTo[int])
+		return err
+	})
+	return ids, more, err
+}
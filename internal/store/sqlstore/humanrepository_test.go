@@ -0,0 +1,45 @@
+package sqlstore
+
+import (
+	"effectiveMobile/internal/model"
+	"effectiveMobile/internal/normalize"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHumanFilterWhereNames(t *testing.T) {
+	where, args := humanFilterWhere(&model.HumanFilter{Name: "Dmitriy", Surname: "100%_"})
+	want := ` WHERE (name ILIKE $1 OR search_key LIKE $2) AND (surname ILIKE $3 OR search_key LIKE $4) AND deleted_at IS NULL`
+	if where != want {
+		t.Errorf("where = %s, want %s", where, want)
+	}
+	wantArgs := []interface{}{"%Dmitriy%", "%dmitri%", "%100%_%", `%10\%\_%`}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %q, want %q", args, wantArgs)
+	}
+}
+
+// TestHumanFilterNameAcrossScripts checks that the key pattern of a name filter
+// is found in the stored key of the same name written in another script
+func TestHumanFilterNameAcrossScripts(t *testing.T) {
+	tests := []struct {
+		filter model.HumanFilter
+		stored [3]string
+	}{
+		{model.HumanFilter{Name: "Dmitriy"}, [3]string{"Дмитрий", "Петров", ""}},
+		{model.HumanFilter{Name: "Дмитрий"}, [3]string{"Dmitrij", "Petrov", ""}},
+		{model.HumanFilter{Surname: "Khrushchev"}, [3]string{"Никита", "Хрущёв", "Сергеевич"}},
+		{model.HumanFilter{Patronymic: "sergeevich"}, [3]string{"Никита", "Хрущёв", "Сергеевич"}},
+	}
+	for _, tt := range tests {
+		_, args := humanFilterWhere(&tt.filter)
+		if len(args) != 2 {
+			t.Fatalf("%+v: args = %q, want the ILIKE and the search key patterns", tt.filter, args)
+		}
+		key := strings.Trim(args[1].(string), "%")
+		if stored := normalize.SearchKey(tt.stored[:]...); !strings.Contains(stored, key) {
+			t.Errorf("%+v: pattern %q does not match the stored key %q", tt.filter, key, stored)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package sqlstore
+
+import (
+	"effectiveMobile/internal/model"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	sort := model.WithTieBreaker([]model.SortField{{Column: "age", Desc: true}, {Column: "created_at"}})
+	c := &model.Cursor{Sort: model.FormatSort(sort), Values: []string{"30", "2025-05-25T12:00:00.5Z", "7"}}
+
+	cond, args, err := keysetCondition(sort, c, []interface{}{"filter arg"})
+	if err != nil {
+		t.Fatalf("keysetCondition error: %v", err)
+	}
+	want := `(("age" < $2) OR ("age" = $3 AND "created_at" > $4) OR ("age" = $5 AND "created_at" = $6 AND "id" > $7))`
+	if cond != want {
+		t.Errorf("condition = %s, want %s", cond, want)
+	}
+	created := time.Date(2025, 5, 25, 12, 0, 0, 500000000, time.UTC)
+	wantArgs := []interface{}{"filter arg", 30, 30, created, 30, created, 7}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
+
+// TestKeysetConditionTampered checks values of a cursor edited by the client
+func TestKeysetConditionTampered(t *testing.T) {
+	sort := model.WithTieBreaker([]model.SortField{{Column: "updated_at"}})
+	for _, values := range [][]string{
+		{"2025-05-25T12:00:00Z", "1 OR 1=1"},
+		{"yesterday", "1"},
+		{"2025-05-25T12:00:00Z"},
+	} {
+		c := &model.Cursor{Sort: model.FormatSort(sort), Values: values}
+		if _, _, err := keysetCondition(sort, c, nil); !errors.Is(err, model.ErrInvalidCursor) {
+			t.Errorf("keysetCondition(%q) error = %v, want ErrInvalidCursor", values, err)
+		}
+	}
+}
@@ -1,13 +1,16 @@
 package sqlstore
 
 import (
+	"context"
 	"effectiveMobile/internal/store"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Store struct {
-	db              *pgxpool.Pool
-	humanRepository *HumanRepository
+	db                        *pgxpool.Pool
+	humanRepository           *HumanRepository
+	enrichmentCacheRepository *EnrichmentCacheRepository
 }
 
 func New(db *pgxpool.Pool) *Store {
@@ -25,3 +28,26 @@ func (s *Store) Human() store.HumanRepository {
 	}
 	return s.humanRepository
 }
+
+func (s *Store) EnrichmentCache() store.EnrichmentCacheRepository {
+	if s.enrichmentCacheRepository != nil {
+		return s.enrichmentCacheRepository
+	}
+	s.enrichmentCacheRepository = &EnrichmentCacheRepository{
+		store: s,
+	}
+	return s.enrichmentCacheRepository
+}
+
+// beginFunc runs fn in a transaction tagged with the audit of ctx,
+// which the people_history trigger records along with every change
+func (s *Store) beginFunc(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	return pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		a := store.AuditFrom(ctx)
+		const query = `SELECT set_config('app.actor', $1, true), set_config('app.request_id', $2, true)`
+		if _, err := tx.Exec(ctx, query, a.Actor, a.RequestID); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
@@ -2,4 +2,5 @@ package store
 
 type Store interface {
 	Human() HumanRepository
+	EnrichmentCache() EnrichmentCacheRepository
 }
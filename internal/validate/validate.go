@@ -0,0 +1,201 @@
+// Package validate checks struct fields against rules declared in `validate` tags:
+//
+//	required      the value must not be empty
+//	min=N, max=N  bounds of a number, or of the length of a string in characters
+//	oneof=a b c   the value must be one of the listed words
+//	iso3166       the value must be an ISO 3166-1 alpha-2 country code
+//	name          the value may hold only letters, spaces, hyphens, apostrophes and dots
+//
+// Rules other than required are skipped for empty strings, so optional fields may be left out.
+// Pointer fields are checked by the value they point to; a nil pointer is empty.
+// Fields are reported by their JSON names.
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error describes a field that breaks a rule
+type Error struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// Error codes
+const (
+	CodeRequired          = "required"
+	CodeTooShort          = "too_short"
+	CodeTooLong           = "too_long"
+	CodeTooSmall          = "too_small"
+	CodeTooLarge          = "too_large"
+	CodeInvalidValue      = "invalid_value"
+	CodeInvalidCountry    = "invalid_country"
+	CodeInvalidCharacters = "invalid_characters"
+)
+
+type rule struct {
+	name  string
+	n     int64
+	words []string
+}
+
+type field struct {
+	index int
+	name  string
+	rules []rule
+}
+
+// fields caches the parsed rules of struct types
+var fields sync.Map
+
+// Struct checks the fields of v, a struct or a pointer to one, and returns every broken rule
+// in the order of the fields. It panics on a malformed tag.
+func Struct(v interface{}) []Error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	var errs []Error
+	for _, f := range typeFields(rv.Type()) {
+		fv := rv.Field(f.index)
+		for _, r := range f.rules {
+			if e, ok := check(f.name, fv, r); !ok {
+				errs = append(errs, e)
+				if r.name == "required" {
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func typeFields(t reflect.Type) []field {
+	if cached, ok := fields.Load(t); ok {
+		return cached.([]field)
+	}
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "" {
+			name = sf.Name
+		}
+		f := field{index: i, name: name}
+		for _, spec := range strings.Split(tag, ",") {
+			f.rules = append(f.rules, parseRule(t, sf, spec))
+		}
+		result = append(result, f)
+	}
+	fields.Store(t, result)
+	return result
+}
+
+func parseRule(t reflect.Type, sf reflect.StructField, spec string) rule {
+	name, arg, _ := strings.Cut(spec, "=")
+	r := rule{name: name}
+	switch name {
+	case "required", "iso3166", "name":
+	case "min", "max":
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validate: %s.%s: bad bound %q", t.Name(), sf.Name, spec))
+		}
+		r.n = n
+	case "oneof":
+		r.words = strings.Fields(arg)
+	default:
+		panic(fmt.Sprintf("validate: %s.%s: unknown rule %q", t.Name(), sf.Name, spec))
+	}
+	return r
+}
+
+// check applies r to the value of a field and describes the failure
+func check(name string, v reflect.Value, r rule) (Error, bool) {
+	fail := func(code, format string, args ...interface{}) (Error, bool) {
+		return Error{Field: name, Code: code, Message: name + " " + fmt.Sprintf(format, args...)}, false
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if r.name == "required" {
+				return fail(CodeRequired, "is required")
+			}
+			return Error{}, true
+		}
+		v = v.Elem()
+	}
+	if r.name == "required" {
+		if v.IsZero() {
+			return fail(CodeRequired, "is required")
+		}
+		return Error{}, true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		if s == "" {
+			return Error{}, true
+		}
+		switch r.name {
+		case "min":
+			if int64(utf8.RuneCountInString(s)) < r.n {
+				return fail(CodeTooShort, "must be at least %d characters long", r.n)
+			}
+		case "max":
+			if int64(utf8.RuneCountInString(s)) > r.n {
+				return fail(CodeTooLong, "must be at most %d characters long", r.n)
+			}
+		case "oneof":
+			for _, w := range r.words {
+				if s == w {
+					return Error{}, true
+				}
+			}
+			return fail(CodeInvalidValue, "must be one of: %s", strings.Join(r.words, ", "))
+		case "iso3166":
+			if !countries[s] {
+				return fail(CodeInvalidCountry, "must be an ISO 3166-1 alpha-2 country code such as RU")
+			}
+		case "name":
+			if !isName(s) {
+				return fail(CodeInvalidCharacters, "may contain only letters, spaces, hyphens, apostrophes and dots")
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		switch r.name {
+		case "min":
+			if n < r.n {
+				return fail(CodeTooSmall, "must be at least %d", r.n)
+			}
+		case "max":
+			if n > r.n {
+				return fail(CodeTooLarge, "must be at most %d", r.n)
+			}
+		}
+	}
+	return Error{}, true
+}
+
+// isName reports whether s consists of letters with their combining marks, spaces, hyphens,
+// apostrophes and dots, as in "Jean-Luc", "O'Neil" or "St. John"
+func isName(s string) bool {
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r), unicode.Is(unicode.Mn, r):
+		case r == ' ', r == '-', r == '\'', r == '’', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
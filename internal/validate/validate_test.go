@@ -0,0 +1,165 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type person struct {
+	Name        string `json:"name" validate:"required,min=2,max=5,name"`
+	Patronymic  string `json:"patronymic,omitempty" validate:"max=5,name"`
+	Age         int    `json:"age" validate:"min=0,max=150"`
+	Gender      string `json:"gender" validate:"oneof=male female unknown"`
+	Nationality string `json:"nationality" validate:"iso3166"`
+	Nickname    string `validate:"max=3"`
+	Comment     string `json:"comment"`
+}
+
+func valid() person {
+	return person{Name: "Ivan", Age: 30, Gender: "male", Nationality: "RU"}
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(p *person)
+		want   []Error
+	}{
+		{"valid", func(p *person) {}, nil},
+		{"optional fields left out", func(p *person) { *p = person{Name: "Ivan"} }, nil},
+
+		{"required", func(p *person) { p.Name = "" }, []Error{
+			{Field: "name", Code: CodeRequired, Message: "name is required"},
+		}},
+		{"min of a string", func(p *person) { p.Name = "I" }, []Error{
+			{Field: "name", Code: CodeTooShort, Message: "name must be at least 2 characters long"},
+		}},
+		{"max of a string", func(p *person) { p.Name = "Ivanov" }, []Error{
+			{Field: "name", Code: CodeTooLong, Message: "name must be at most 5 characters long"},
+		}},
+		{"length in characters", func(p *person) { p.Name = "Фёдор" }, nil},
+		{"min of a number", func(p *person) { p.Age = -1 }, []Error{
+			{Field: "age", Code: CodeTooSmall, Message: "age must be at least 0"},
+		}},
+		{"max of a number", func(p *person) { p.Age = 151 }, []Error{
+			{Field: "age", Code: CodeTooLarge, Message: "age must be at most 150"},
+		}},
+		{"bounds are inclusive", func(p *person) { p.Age = 150 }, nil},
+		{"oneof", func(p *person) { p.Gender = "Male" }, []Error{
+			{Field: "gender", Code: CodeInvalidValue, Message: "gender must be one of: male, female, unknown"},
+		}},
+		{"iso3166", func(p *person) { p.Nationality = "XX" }, []Error{
+			{Field: "nationality", Code: CodeInvalidCountry, Message: "nationality must be an ISO 3166-1 alpha-2 country code such as RU"},
+		}},
+		{"iso3166 is case-sensitive", func(p *person) { p.Nationality = "ru" }, []Error{
+			{Field: "nationality", Code: CodeInvalidCountry, Message: "nationality must be an ISO 3166-1 alpha-2 country code such as RU"},
+		}},
+		{"name", func(p *person) { p.Patronymic = "Iv4n" }, []Error{
+			{Field: "patronymic", Code: CodeInvalidCharacters, Message: "patronymic may contain only letters, spaces, hyphens, apostrophes and dots"},
+		}},
+
+		// поле без тега json называется по имени поля Go, поле без тега validate не проверяется
+		{"field without a JSON name", func(p *person) { p.Nickname = "long"; p.Comment = "!!!" }, []Error{
+			{Field: "Nickname", Code: CodeTooLong, Message: "Nickname must be at most 3 characters long"},
+		}},
+		// после нарушения required остальные правила поля не проверяются, ошибки идут в порядке полей
+		{"several fields", func(p *person) { *p = person{Age: 200, Gender: "x"} }, []Error{
+			{Field: "name", Code: CodeRequired, Message: "name is required"},
+			{Field: "age", Code: CodeTooLarge, Message: "age must be at most 150"},
+			{Field: "gender", Code: CodeInvalidValue, Message: "gender must be one of: male, female, unknown"},
+		}},
+		{"several rules of a field", func(p *person) { p.Name = "I1" + strings.Repeat("a", 4) }, []Error{
+			{Field: "name", Code: CodeTooLong, Message: "name must be at most 5 characters long"},
+			{Field: "name", Code: CodeInvalidCharacters, Message: "name may contain only letters, spaces, hyphens, apostrophes and dots"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.change(&p)
+			if got := Struct(&p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct = %+v, want %+v", got, tt.want)
+			}
+			// значение и указатель проверяются одинаково
+			if got := Struct(p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct of a value = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructPointerFields(t *testing.T) {
+	type patch struct {
+		Name *string `json:"name" validate:"max=3,name"`
+		Age  *int    `json:"age" validate:"required,max=150"`
+	}
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+
+	tests := []struct {
+		name string
+		in   patch
+		want []string
+	}{
+		{"nil is empty", patch{Age: num(1)}, nil},
+		{"nil fails required", patch{}, []string{"age:required"}},
+		{"pointed value is checked", patch{Name: str("Ivan"), Age: num(151)}, []string{"name:too_long", "age:too_large"}},
+		{"pointer to an empty value", patch{Name: str(""), Age: num(0)}, []string{"age:required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range Struct(&tt.in) {
+				got = append(got, e.Field+":"+e.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestName(t *testing.T) {
+	for _, s := range []string{"Jean-Luc", "O'Neil", "O’Neil", "St. John", "Пётр", "José", "Jose\u0301", "李"} {
+		if !isName(s) {
+			t.Errorf("isName(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"Ivan1", "Ivan_", "<script>", "Ivan\tIvanov", "😀"} {
+		if isName(s) {
+			t.Errorf("isName(%q) = true, want false", s)
+		}
+	}
+}
+
+func TestCountries(t *testing.T) {
+	if len(countries) != 249 {
+		t.Errorf("%d countries, want the 249 codes assigned by ISO 3166-1", len(countries))
+	}
+	for _, code := range []string{"RU", "KZ", "US", "SS", "XK"} {
+		if want := code != "XK"; countries[code] != want {
+			t.Errorf("countries[%q] = %v, want %v", code, countries[code], want)
+		}
+	}
+}
+
+func TestMalformedTag(t *testing.T) {
+	for _, v := range []interface{}{
+		&struct {
+			A string `validate:"max=x"`
+		}{},
+		&struct {
+			A string `validate:"email"`
+		}{},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Struct(%T) did not panic", v)
+				}
+			}()
+			Struct(v)
+		}()
+	}
+}
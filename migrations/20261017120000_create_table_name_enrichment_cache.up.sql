@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS name_enrichment_cache (
+                        provider varchar(64) not null,
+                        name varchar(255) not null,
+                        result jsonb not null,
+                        fetched_at timestamptz not null default now(),
+                        primary key (provider, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_name_enrichment_cache_name
+    ON name_enrichment_cache(name);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS people_enrichment (
+                        human_id int not null references people(id) on delete cascade,
+                        attribute varchar(32) not null,
+                        value varchar(255) not null,
+                        provider varchar(64) not null,
+                        probability double precision,
+                        sample_count int not null default 0,
+                        fetched_at timestamptz not null,
+                        primary key (human_id, attribute)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_people_nationalities_country_probability;
+
+DROP TABLE IF EXISTS people_nationalities;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS people_nationalities (
+                        human_id int not null references people(id) on delete cascade,
+                        country_id char(2) not null,
+                        probability double precision not null,
+                        rank smallint not null,
+                        primary key (human_id, country_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_nationalities_country_probability
+    ON people_nationalities(country_id, probability);
+
+-- Переносим уже сохранённые национальности как единственного кандидата
+INSERT INTO people_nationalities (human_id, country_id, probability, rank)
+SELECT id, nationality, 1, 1
+  FROM people
+ WHERE nationality IS NOT NULL AND trim(nationality) <> ''
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_people_enrichment_status;
+
+ALTER TABLE people
+    DROP COLUMN IF EXISTS enriched_at,
+    DROP COLUMN IF EXISTS enrichment_error,
+    DROP COLUMN IF EXISTS enrichment_status;
+
+DROP TYPE IF EXISTS enrichment_status;
@@ -0,0 +1,13 @@
+CREATE TYPE enrichment_status AS ENUM ('pending', 'enriched', 'failed');
+
+-- Записи, созданные до появления фонового обогащения, уже обогащены синхронно
+ALTER TABLE people
+    ADD COLUMN enrichment_status enrichment_status not null default 'enriched',
+    ADD COLUMN enrichment_error text,
+    ADD COLUMN enriched_at timestamptz;
+
+ALTER TABLE people
+    ALTER COLUMN enrichment_status SET DEFAULT 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_people_enrichment_status
+    ON people(enrichment_status);
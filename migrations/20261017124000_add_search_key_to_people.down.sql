@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_people_search_key_missing;
+DROP INDEX IF EXISTS idx_people_search_key_trgm;
+
+ALTER TABLE people
+    DROP COLUMN IF EXISTS search_key;
@@ -0,0 +1,9 @@
+-- Ключ заполняется приложением; у старых записей он NULL до фонового заполнения при запуске
+ALTER TABLE people
+    ADD COLUMN search_key text;
+
+CREATE INDEX IF NOT EXISTS idx_people_search_key_trgm
+    ON people USING gin (search_key gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_people_search_key_missing
+    ON people(id) WHERE search_key IS NULL;
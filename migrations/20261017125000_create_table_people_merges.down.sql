@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_people_merges_survivor_id;
+
+DROP TABLE IF EXISTS people_merges;
@@ -0,0 +1,11 @@
+-- Без внешних ключей: история слияний должна пережить удаление записей
+CREATE TABLE IF NOT EXISTS people_merges (
+                        id bigserial primary key,
+                        survivor_id int not null,
+                        merged_id int not null,
+                        merged_record jsonb not null,
+                        merged_at timestamptz not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_merges_survivor_id
+    ON people_merges(survivor_id);
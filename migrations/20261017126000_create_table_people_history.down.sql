@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS people_history_record ON people;
+
+DROP FUNCTION IF EXISTS people_history_record();
+
+DROP INDEX IF EXISTS idx_people_history_human_id;
+
+DROP TABLE IF EXISTS people_history;
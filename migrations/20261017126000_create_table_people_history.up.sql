@@ -0,0 +1,59 @@
+-- Журнал изменений записей people, пополняется только триггером.
+-- Без внешних ключей: история должна пережить удаление записи
+CREATE TABLE IF NOT EXISTS people_history (
+                        id bigserial primary key,
+                        human_id int not null,
+                        action varchar(16) not null,
+                        old_values jsonb,
+                        new_values jsonb,
+                        actor text not null default '',
+                        request_id text not null default '',
+                        changed_at timestamptz not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_history_human_id
+    ON people_history(human_id, id);
+
+-- Автор и идентификатор запроса передаются приложением через локальные настройки транзакции
+-- app.actor и app.request_id. Для изменений хранятся только поля, значение которых поменялось;
+-- служебный ключ поиска в журнал не попадает.
+CREATE OR REPLACE FUNCTION people_history_record() RETURNS trigger AS $$
+DECLARE
+    old_row jsonb;
+    new_row jsonb;
+    old_values jsonb;
+    new_values jsonb;
+    target_id int;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        target_id := NEW.id;
+        new_values := to_jsonb(NEW) - 'search_key';
+    ELSIF TG_OP = 'DELETE' THEN
+        target_id := OLD.id;
+        old_values := to_jsonb(OLD) - 'search_key';
+    ELSE
+        target_id := NEW.id;
+        old_row := to_jsonb(OLD) - 'search_key';
+        new_row := to_jsonb(NEW) - 'search_key';
+        IF old_row = new_row THEN
+            RETURN NULL;
+        END IF;
+        SELECT jsonb_object_agg(o.key, o.value) INTO old_values
+          FROM jsonb_each(old_row) o
+         WHERE new_row -> o.key IS DISTINCT FROM o.value;
+        SELECT jsonb_object_agg(n.key, n.value) INTO new_values
+          FROM jsonb_each(new_row) n
+         WHERE old_row -> n.key IS DISTINCT FROM n.value;
+    END IF;
+
+    INSERT INTO people_history (human_id, action, old_values, new_values, actor, request_id)
+    VALUES (target_id, lower(TG_OP), old_values, new_values,
+            COALESCE(current_setting('app.actor', true), ''),
+            COALESCE(current_setting('app.request_id', true), ''));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER people_history_record
+    AFTER INSERT OR UPDATE OR DELETE ON people
+    FOR EACH ROW EXECUTE FUNCTION people_history_record();
@@ -0,0 +1,42 @@
+-- Прежняя версия функции журнала, без мягкого удаления
+CREATE OR REPLACE FUNCTION people_history_record() RETURNS trigger AS $$
+DECLARE
+    old_row jsonb;
+    new_row jsonb;
+    old_values jsonb;
+    new_values jsonb;
+    target_id int;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        target_id := NEW.id;
+        new_values := to_jsonb(NEW) - 'search_key';
+    ELSIF TG_OP = 'DELETE' THEN
+        target_id := OLD.id;
+        old_values := to_jsonb(OLD) - 'search_key';
+    ELSE
+        target_id := NEW.id;
+        old_row := to_jsonb(OLD) - 'search_key';
+        new_row := to_jsonb(NEW) - 'search_key';
+        IF old_row = new_row THEN
+            RETURN NULL;
+        END IF;
+        SELECT jsonb_object_agg(o.key, o.value) INTO old_values
+          FROM jsonb_each(old_row) o
+         WHERE new_row -> o.key IS DISTINCT FROM o.value;
+        SELECT jsonb_object_agg(n.key, n.value) INTO new_values
+          FROM jsonb_each(new_row) n
+         WHERE old_row -> n.key IS DISTINCT FROM n.value;
+    END IF;
+
+    INSERT INTO people_history (human_id, action, old_values, new_values, actor, request_id)
+    VALUES (target_id, lower(TG_OP), old_values, new_values,
+            COALESCE(current_setting('app.actor', true), ''),
+            COALESCE(current_setting('app.request_id', true), ''));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_people_deleted_at;
+
+ALTER TABLE people
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,54 @@
+-- Мягкое удаление: записи с deleted_at скрыты из выборок и удаляются окончательно
+-- фоновой очисткой по истечении срока хранения
+ALTER TABLE people
+    ADD COLUMN deleted_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_people_deleted_at
+    ON people(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Мягкое удаление и восстановление записываются в историю отдельными действиями,
+-- окончательное удаление — как purge
+CREATE OR REPLACE FUNCTION people_history_record() RETURNS trigger AS $$
+DECLARE
+    old_row jsonb;
+    new_row jsonb;
+    old_values jsonb;
+    new_values jsonb;
+    target_id int;
+    action text;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        target_id := NEW.id;
+        action := 'insert';
+        new_values := to_jsonb(NEW) - 'search_key';
+    ELSIF TG_OP = 'DELETE' THEN
+        target_id := OLD.id;
+        action := 'purge';
+        old_values := to_jsonb(OLD) - 'search_key';
+    ELSE
+        target_id := NEW.id;
+        action := CASE
+            WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+            WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+            ELSE 'update'
+        END;
+        old_row := to_jsonb(OLD) - 'search_key';
+        new_row := to_jsonb(NEW) - 'search_key';
+        IF old_row = new_row THEN
+            RETURN NULL;
+        END IF;
+        SELECT jsonb_object_agg(o.key, o.value) INTO old_values
+          FROM jsonb_each(old_row) o
+         WHERE new_row -> o.key IS DISTINCT FROM o.value;
+        SELECT jsonb_object_agg(n.key, n.value) INTO new_values
+          FROM jsonb_each(new_row) n
+         WHERE old_row -> n.key IS DISTINCT FROM n.value;
+    END IF;
+
+    INSERT INTO people_history (human_id, action, old_values, new_values, actor, request_id)
+    VALUES (target_id, action, old_values, new_values,
+            COALESCE(current_setting('app.actor', true), ''),
+            COALESCE(current_setting('app.request_id', true), ''));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
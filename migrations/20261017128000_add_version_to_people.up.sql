@@ -0,0 +1,4 @@
+-- Версия записи для оптимистической блокировки: увеличивается при каждом изменении,
+-- видимом клиенту, и служит ETag
+ALTER TABLE people
+    ADD COLUMN version int not null default 1;
@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS people_touch_updated_at ON people;
+
+DROP FUNCTION IF EXISTS people_touch_updated_at();
+
+DROP INDEX IF EXISTS idx_people_updated_at;
+
+DROP INDEX IF EXISTS idx_people_created_at;
+
+ALTER TABLE people
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at;
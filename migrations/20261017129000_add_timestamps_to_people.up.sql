@@ -0,0 +1,39 @@
+ALTER TABLE people
+    ADD COLUMN created_at timestamptz not null default now(),
+    ADD COLUMN updated_at timestamptz not null default now();
+
+-- Для записей, появившихся после журнала изменений, время берётся из него.
+-- Журнал на время переноса отключён, чтобы перенос не попал в историю.
+ALTER TABLE people DISABLE TRIGGER people_history_record;
+
+UPDATE people p
+   SET created_at = COALESCE(h.created_at, p.created_at),
+       updated_at = h.updated_at
+  FROM (SELECT human_id,
+               min(changed_at) FILTER (WHERE action = 'insert') AS created_at,
+               max(changed_at) AS updated_at
+          FROM people_history
+         GROUP BY human_id) h
+ WHERE h.human_id = p.id;
+
+ALTER TABLE people ENABLE TRIGGER people_history_record;
+
+CREATE INDEX IF NOT EXISTS idx_people_created_at
+    ON people(created_at);
+
+CREATE INDEX IF NOT EXISTS idx_people_updated_at
+    ON people(updated_at);
+
+-- updated_at меняется при любом изменении, кроме служебного ключа поиска
+CREATE OR REPLACE FUNCTION people_touch_updated_at() RETURNS trigger AS $$
+BEGIN
+    IF (to_jsonb(NEW) - 'search_key' - 'updated_at') IS DISTINCT FROM (to_jsonb(OLD) - 'search_key' - 'updated_at') THEN
+        NEW.updated_at := now();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER people_touch_updated_at
+    BEFORE UPDATE ON people
+    FOR EACH ROW EXECUTE FUNCTION people_touch_updated_at();